@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// --- HTTP/JSON API ---
+//
+//	POST   /sessions               {"player": "анна"} (тело необязательно) -> 201 {"session", "state"}
+//	POST   /sessions/{id}/commands {"command": "идти коридор"}            -> 200 {"answer", "message", "events", "state", "messages"}
+//	GET    /sessions/{id}/messages                                         -> 200 {"messages"}
+//	DELETE /sessions/{id}                                                  -> 204
+//
+// messages — накопившиеся сообщения от других игроков, каждое отдаётся один раз.
+//
+// Без имени игрок получает имя гостя. Тело запроса — не больше maxRequestBody байт.
+//
+// Ошибки приходят как {"error": "..."} с кодом 400, 404, 413 или 503.
+// Сессия, к которой не обращались дольше IdleTimeout, закрывается:
+// игрок выходит из мира, как при обрыве telnet-соединения.
+
+const (
+	apiSessionNotFound = "нет такой сессии"
+	apiTooManySessions = "слишком много сессий"
+	apiBadRequest      = "некорректный запрос"
+	apiTooLarge        = "слишком большой запрос"
+
+	maxRequestBody = 4 << 10
+)
+
+// API — HTTP-фронтенд игры. Каждая сессия — отдельный игрок в общем мире.
+type API struct {
+	World       *World
+	MaxSessions int              // 0 — без ограничения
+	IdleTimeout time.Duration    // 0 — сессии не истекают
+	Now         func() time.Time // по умолчанию time.Now, подменяется в тестах
+
+	once     sync.Once
+	mux      *http.ServeMux
+	mu       sync.Mutex
+	sessions map[string]*apiSession
+	nextID   int
+}
+
+type apiSession struct {
+	*Session
+	lastSeen time.Time
+}
+
+type createRequest struct {
+	Player string `json:"player,omitempty"`
+}
+
+type createResponse struct {
+	Session string `json:"session"`
+	State   State  `json:"state"`
+}
+
+type commandRequest struct {
+	Command string `json:"command"`
+}
+
+// commandResponse — структурированный ответ и его текст, как в telnet
+type commandResponse struct {
+	Answer string `json:"answer"`
+	Result
+	Messages []string `json:"messages,omitempty"`
+}
+
+type messagesResponse struct {
+	Messages []string `json:"messages"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (a *API) init() {
+	a.once.Do(func() {
+		if a.Now == nil {
+			a.Now = time.Now
+		}
+		a.sessions = make(map[string]*apiSession)
+		a.mux = http.NewServeMux()
+		a.mux.HandleFunc("POST /sessions", a.handleCreate)
+		a.mux.HandleFunc("POST /sessions/{id}/commands", a.handleCommand)
+		a.mux.HandleFunc("GET /sessions/{id}/messages", a.handleMessages)
+		a.mux.HandleFunc("DELETE /sessions/{id}", a.handleDelete)
+	})
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.init()
+	a.Expire()
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+	a.mux.ServeHTTP(w, r)
+}
+
+// Expire закрывает сессии, бездействующие дольше IdleTimeout.
+// Вызывается на каждом запросе; серверу стоит вызывать его и по таймеру.
+func (a *API) Expire() {
+	a.init()
+	if a.IdleTimeout <= 0 {
+		return
+	}
+	now := a.Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for id, s := range a.sessions {
+		if now.Sub(s.lastSeen) > a.IdleTimeout {
+			s.Leave()
+			delete(a.sessions, id)
+		}
+	}
+}
+
+func (a *API) handleCreate(w http.ResponseWriter, r *http.Request) {
+	req := createRequest{}
+	if r.ContentLength != 0 && !decodeRequest(w, r, &req) {
+		return
+	}
+
+	a.mu.Lock()
+	if a.MaxSessions > 0 && len(a.sessions) >= a.MaxSessions {
+		a.mu.Unlock()
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{apiTooManySessions})
+		return
+	}
+	if req.Player == "" {
+		a.nextID++
+		req.Player = fmt.Sprintf("гость-%d", a.nextID)
+	}
+	sess, err := a.World.Join(req.Player)
+	if err != nil {
+		a.mu.Unlock()
+		writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+		return
+	}
+	id := newSessionID()
+	a.sessions[id] = &apiSession{Session: sess, lastSeen: a.Now()}
+	a.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, createResponse{Session: id, State: sess.State()})
+}
+
+func (a *API) handleCommand(w http.ResponseWriter, r *http.Request) {
+	sess, ok := a.session(r.PathValue("id"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse{apiSessionNotFound})
+		return
+	}
+	req := commandRequest{}
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if req.Command == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{apiBadRequest + ": пустая команда"})
+		return
+	}
+	res := sess.Result(req.Command)
+	writeJSON(w, http.StatusOK, commandResponse{Answer: res.Text(), Result: res, Messages: sess.Messages()})
+}
+
+func (a *API) handleMessages(w http.ResponseWriter, r *http.Request) {
+	sess, ok := a.session(r.PathValue("id"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse{apiSessionNotFound})
+		return
+	}
+	msgs := sess.Messages()
+	if msgs == nil {
+		msgs = []string{}
+	}
+	writeJSON(w, http.StatusOK, messagesResponse{Messages: msgs})
+}
+
+func (a *API) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	a.mu.Lock()
+	sess, ok := a.sessions[id]
+	delete(a.sessions, id)
+	a.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse{apiSessionNotFound})
+		return
+	}
+	sess.Leave()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// session находит сессию и отмечает обращение к ней
+func (a *API) session(id string) (*Session, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s, ok := a.sessions[id]
+	if !ok {
+		return nil, false
+	}
+	s.lastSeen = a.Now()
+	return s.Session, true
+}
+
+// decodeRequest читает JSON из тела запроса; при ошибке отвечает клиенту сам
+func decodeRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{apiTooLarge})
+	case err != nil:
+		writeJSON(w, http.StatusBadRequest, errorResponse{apiBadRequest + ": " + err.Error()})
+	}
+	return err == nil
+}
+
+// newSessionID — случайный идентификатор, по которому сессию не угадать
+func newSessionID() string {
+	b := make([]byte, 16)
+	rand.Read(b) // nolint:errcheck // crypto/rand.Read не возвращает ошибок
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v) // nolint:errcheck
+}
+
+// serveAPI запускает HTTP API на addr и останавливает его по отмене ctx
+func serveAPI(ctx context.Context, addr string, api *API) error {
+	srv := &http.Server{Addr: addr, Handler: api}
+	stop := context.AfterFunc(ctx, func() {
+		srv.Shutdown(context.Background()) // nolint:errcheck
+	})
+	defer stop()
+	if api.IdleTimeout > 0 {
+		go func() {
+			t := time.NewTicker(api.IdleTimeout)
+			defer t.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-t.C:
+					api.Expire()
+				}
+			}
+		}()
+	}
+	fmt.Println("HTTP API слушает", addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
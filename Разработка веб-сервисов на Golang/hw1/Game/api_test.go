@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func apiRequest(t *testing.T, method, url string, body, out any) int {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	if body != nil {
+		if err := json.NewEncoder(buf).Encode(body); err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+	}
+	req, err := http.NewRequest(method, url, buf)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestAPIPlaysGame(t *testing.T) {
+	initGame()
+	srv := httptest.NewServer(&API{World: world})
+	defer srv.Close()
+
+	created := createResponse{}
+	if code := apiRequest(t, "POST", srv.URL+"/sessions", createRequest{Player: "анна"}, &created); code != http.StatusCreated {
+		t.Fatalf("create: status %d", code)
+	}
+	if created.Session == "" || created.State.Player != "анна" || created.State.Room != "кухня" {
+		t.Errorf("create: unexpected response %+v", created)
+	}
+	url := srv.URL + "/sessions/" + created.Session
+
+	steps := []struct {
+		command string
+		answer  string
+		state   State
+	}{
+		{"идти коридор", "ничего интересного. можно пройти - кухня, комната, улица", State{
+			Player: "анна", Room: "коридор", Exits: []Exit{{Name: "кухня", To: "кухня"}, {Name: "комната", To: "комната"}, {Name: "улица", To: "улица", Locked: true, Lock: "дверь"}},
+			Items: []string{}, Inventory: []string{}, Worn: []string{},
+		}},
+		{"идти комната", "ты в своей комнате. можно пройти - коридор", State{
+			Player: "анна", Room: "комната", Exits: []Exit{{Name: "коридор", To: "коридор"}},
+			Items: []string{"ключи", "конспекты", "рюкзак"}, Inventory: []string{}, Worn: []string{},
+		}},
+		{"надеть рюкзак", "вы надели: рюкзак", State{
+			Player: "анна", Room: "комната", Exits: []Exit{{Name: "коридор", To: "коридор"}},
+			Items: []string{"ключи", "конспекты"}, Inventory: []string{}, Worn: []string{"рюкзак"},
+		}},
+		{"взять ключи", "предмет добавлен в инвентарь: ключи", State{
+			Player: "анна", Room: "комната", Exits: []Exit{{Name: "коридор", To: "коридор"}},
+			Items: []string{"конспекты"}, Inventory: []string{"ключи"}, Worn: []string{"рюкзак"},
+		}},
+	}
+	for i, st := range steps {
+		resp := commandResponse{}
+		if code := apiRequest(t, "POST", url+"/commands", commandRequest{st.command}, &resp); code != http.StatusOK {
+			t.Fatalf("step %d: status %d", i+1, code)
+		}
+		if resp.Answer != st.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", i+1, st.command, resp.Answer, st.answer)
+		}
+		if !reflect.DeepEqual(resp.State, st.state) {
+			t.Errorf("step %d: cmd %q: got state %+v, want %+v", i+1, st.command, resp.State, st.state)
+		}
+	}
+
+	errResp := errorResponse{}
+	if code := apiRequest(t, "POST", url+"/commands", commandRequest{}, &errResp); code != http.StatusBadRequest {
+		t.Errorf("empty command: status %d", code)
+	}
+	if code := apiRequest(t, "POST", srv.URL+"/sessions", createRequest{Player: "анна"}, &errResp); code != http.StatusBadRequest {
+		t.Errorf("duplicate player: status %d", code)
+	}
+
+	if code := apiRequest(t, "DELETE", url, nil, nil); code != http.StatusNoContent {
+		t.Errorf("delete: status %d", code)
+	}
+	// ушедший игрок оставляет вещи в комнате
+	if world.rooms["комната"].items["ключи"] == nil {
+		t.Errorf("keys were not left in the room")
+	}
+	if code := apiRequest(t, "POST", url+"/commands", commandRequest{"осмотреться"}, &errResp); code != http.StatusNotFound {
+		t.Errorf("command after delete: status %d", code)
+	}
+	if errResp.Error != apiSessionNotFound {
+		t.Errorf("command after delete: error %q", errResp.Error)
+	}
+}
+
+func TestAPIExpiresIdleSessions(t *testing.T) {
+	initGame()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	api := &API{World: world, IdleTimeout: time.Minute, MaxSessions: 1, Now: func() time.Time { return now }}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	created := createResponse{}
+	if code := apiRequest(t, "POST", srv.URL+"/sessions", nil, &created); code != http.StatusCreated {
+		t.Fatalf("create: status %d", code)
+	}
+	if created.State.Player != "гость-1" {
+		t.Errorf("generated player: got %q", created.State.Player)
+	}
+	if code := apiRequest(t, "POST", srv.URL+"/sessions", nil, &errorResponse{}); code != http.StatusServiceUnavailable {
+		t.Errorf("second session: status %d", code)
+	}
+
+	url := srv.URL + "/sessions/" + created.Session + "/commands"
+	now = now.Add(50 * time.Second)
+	if code := apiRequest(t, "POST", url, commandRequest{"осмотреться"}, &commandResponse{}); code != http.StatusOK {
+		t.Errorf("active session: status %d", code)
+	}
+	now = now.Add(50 * time.Second) // с последней команды прошло меньше минуты
+	if code := apiRequest(t, "POST", url, commandRequest{"осмотреться"}, &commandResponse{}); code != http.StatusOK {
+		t.Errorf("active session: status %d", code)
+	}
+	now = now.Add(2 * time.Minute)
+	if code := apiRequest(t, "POST", url, commandRequest{"осмотреться"}, &errorResponse{}); code != http.StatusNotFound {
+		t.Errorf("idle session: status %d", code)
+	}
+	if _, ok := world.players["гость-1"]; ok {
+		t.Errorf("idle player is still in the world")
+	}
+}
+
+func TestAPIRejectsBadRequests(t *testing.T) {
+	initGame()
+	srv := httptest.NewServer(&API{World: world})
+	defer srv.Close()
+
+	for _, name := range []string{" ", "анна\nбоб: привет", "\x1b[2J"} {
+		resp := errorResponse{}
+		if code := apiRequest(t, "POST", srv.URL+"/sessions", createRequest{Player: name}, &resp); code != http.StatusBadRequest {
+			t.Errorf("player %q: status %d", name, code)
+		}
+		if !strings.HasPrefix(resp.Error, errBadPlayerID.Error()) {
+			t.Errorf("player %q: error %q", name, resp.Error)
+		}
+	}
+	if len(world.players) != 1 {
+		t.Errorf("rejected players joined the world: %d players", len(world.players))
+	}
+
+	created := createResponse{}
+	if code := apiRequest(t, "POST", srv.URL+"/sessions", nil, &created); code != http.StatusCreated {
+		t.Fatalf("create: status %d", code)
+	}
+	url := srv.URL + "/sessions/" + created.Session + "/commands"
+	huge := commandRequest{strings.Repeat("а", maxRequestBody)}
+	if code := apiRequest(t, "POST", url, huge, &errorResponse{}); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("huge command: status %d", code)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+	"sync"
+)
+
+// --- Общение игроков ---
+//
+// Ответ на команду получает только тот, кто её ввёл. Всё, что игрок должен
+// узнать без своей команды (реплики других, кто пришёл и ушёл), попадает
+// в его очередь сообщений. Очередь пополняется под w.mu и никогда не
+// блокирует: фронтенд забирает сообщения сам, когда сможет.
+
+// maxOutbox — сколько сообщений копится у игрока, старые вытесняются новыми
+const maxOutbox = 100
+
+// outbox — очередь асинхронных сообщений игрока
+type outbox struct {
+	mu     sync.Mutex
+	msgs   []string
+	notify chan struct{} // сигнал, что в очереди что-то появилось
+}
+
+func newOutbox() *outbox {
+	return &outbox{notify: make(chan struct{}, 1)}
+}
+
+func (o *outbox) push(msg string) {
+	o.mu.Lock()
+	if len(o.msgs) >= maxOutbox {
+		o.msgs = o.msgs[1:]
+	}
+	o.msgs = append(o.msgs, msg)
+	o.mu.Unlock()
+	select {
+	case o.notify <- struct{}{}:
+	default: // сигнал уже ждёт
+	}
+}
+
+// drain забирает все накопленные сообщения
+func (o *outbox) drain() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	msgs := o.msgs
+	o.msgs = nil
+	return msgs
+}
+
+// tell отправляет сообщение игрокам в комнате r (nil — во всех комнатах), кроме from.
+// msg переводится на язык каждого получателя.
+func (w *World) tell(r *Room, from *Player, msg string, args ...any) {
+	for _, q := range w.players {
+		if q != from && q.online && (r == nil || q.room == r) && q.outbox != nil {
+			q.outbox.push(q.tr(msg, args...))
+		}
+	}
+}
+
+// neighbours — комнаты, куда ведут выходы из r, без повторов
+func (r *Room) neighbours() []*Room {
+	var rooms []*Room
+	seen := map[*Room]bool{r: true}
+	for _, name := range r.exits {
+		if to := r.paths[name].to; !seen[to] {
+			seen[to] = true
+			rooms = append(rooms, to)
+		}
+	}
+	return rooms
+}
+
+// --- Команды "сказать" и "крикнуть" ---
+
+func (w *World) handleSay(p *Player, args []string) string {
+	text := strings.Join(args, " ")
+	w.tell(p.room, p, "%s говорит: %s", p.id, text)
+	return p.tr("вы сказали: %s", text)
+}
+
+// handleShout слышно в этой комнате и во всех соседних, даже за запертыми дверями
+func (w *World) handleShout(p *Player, args []string) string {
+	text := strings.Join(args, " ")
+	w.tell(p.room, p, "%s кричит: %s", p.id, text)
+	for _, r := range p.room.neighbours() {
+		w.tell(r, p, "%s кричит где-то рядом: %s", p.id, text)
+	}
+	return p.tr("вы крикнули: %s", text)
+}
+
+// --- Очередь сообщений сессии ---
+
+// Notify срабатывает, когда у игрока появились новые сообщения
+func (s *Session) Notify() <-chan struct{} {
+	return s.player.outbox.notify
+}
+
+// Messages забирает накопленные сообщения игрока
+func (s *Session) Messages() []string {
+	return s.player.outbox.drain()
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestChat(t *testing.T) {
+	initGame()
+	anna, err := world.Join("анна")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	boris, err := world.Join("борис")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	steps := []struct {
+		sess   *Session
+		cmd    string
+		answer string
+		heard  []string // что после команды пришло борису
+	}{
+		{anna, "сказать Привет всем", "вы сказали: Привет всем", []string{"анна говорит: Привет всем"}},
+		{anna, "сказать", "что сказать?", nil},
+		{boris, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица", nil},
+		{anna, "сказать ты где?", "вы сказали: ты где?", nil}, // борис уже в другой комнате
+		{anna, "крикнуть Подожди", "вы крикнули: Подожди", []string{"анна кричит где-то рядом: Подожди"}},
+		{anna, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица", []string{"анна входит"}},
+		{anna, "идти комната", "ты в своей комнате. можно пройти - коридор", []string{"анна уходит в комната"}},
+	}
+	for i, st := range steps {
+		if answer := st.sess.Handle(st.cmd); answer != st.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", i+1, st.cmd, answer, st.answer)
+		}
+		if heard := boris.Messages(); !reflect.DeepEqual(heard, st.heard) {
+			t.Errorf("step %d: cmd %q: boris heard %q, want %q", i+1, st.cmd, heard, st.heard)
+		}
+	}
+
+	// игрок по умолчанию остался на кухне и слышал только первую реплику и уход бориса
+	want := []string{"анна говорит: Привет всем", "борис уходит в коридор", "анна говорит: ты где?", "анна кричит: Подожди", "анна уходит в коридор"}
+	if heard := world.players[defaultPlayerID].outbox.drain(); !reflect.DeepEqual(heard, want) {
+		t.Errorf("default player heard %q, want %q", heard, want)
+	}
+
+	// сообщение переводится на язык получателя
+	if err := boris.SetLocale("en"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	boris.Handle("идти комната")
+	anna.Handle("сказать hi")
+	if heard := boris.Messages(); !reflect.DeepEqual(heard, []string{"анна says: hi"}) {
+		t.Errorf("boris heard %q", heard)
+	}
+}
+
+func TestChatNamesCannotForgeLines(t *testing.T) {
+	initGame()
+	for _, id := range []string{"анна\nборис говорит: отдай ключи", "анна\r", "\t", "  "} {
+		if _, err := world.Join(id); !errors.Is(err, errBadPlayerID) {
+			t.Errorf("Join(%q): expected errBadPlayerID, got %v", id, err)
+		}
+	}
+	if len(world.players) != 1 {
+		t.Errorf("rejected players joined the world: %d players", len(world.players))
+	}
+	if heard := world.players[defaultPlayerID].outbox.drain(); heard != nil {
+		t.Errorf("default player heard %q", heard)
+	}
+}
+
+func TestOutboxDropsOldest(t *testing.T) {
+	o := newOutbox()
+	for i := 0; i < maxOutbox+50; i++ {
+		o.push(fmt.Sprint(i))
+	}
+	msgs := o.drain()
+	if len(msgs) != maxOutbox || msgs[0] != "50" {
+		t.Errorf("got %d messages starting with %q", len(msgs), msgs[0])
+	}
+	select {
+	case <-o.notify:
+	default:
+		t.Errorf("no notification")
+	}
+	if msgs := o.drain(); msgs != nil {
+		t.Errorf("drained twice: %q", msgs)
+	}
+}
+
+func TestServerDeliversChat(t *testing.T) {
+	initGame()
+	addr, cancel, _ := startTestServer(t, &Server{World: world})
+	defer cancel()
+
+	c1 := dialTestClient(t, addr)
+	defer c1.conn.Close()
+	c1.readLine(t)
+	c2 := dialTestClient(t, addr)
+	defer c2.conn.Close()
+	c2.readLine(t)
+
+	if answer := c2.send(t, "сказать привет"); answer != "вы сказали: привет" {
+		t.Errorf("unexpected answer %q", answer)
+	}
+	// первый клиент ничего не отправлял, сообщение приходит само
+	if line := c1.readLine(t); line != "игрок-2 говорит: привет" {
+		t.Errorf("unexpected message %q", line)
+	}
+}
+
+func TestAPIMessages(t *testing.T) {
+	initGame()
+	srv := httptest.NewServer(&API{World: world})
+	defer srv.Close()
+
+	sessions := make([]string, 2)
+	for i, name := range []string{"анна", "борис"} {
+		created := createResponse{}
+		if code := apiRequest(t, "POST", srv.URL+"/sessions", createRequest{Player: name}, &created); code != http.StatusCreated {
+			t.Fatalf("create: status %d", code)
+		}
+		sessions[i] = srv.URL + "/sessions/" + created.Session
+	}
+
+	apiRequest(t, "POST", sessions[0]+"/commands", commandRequest{"сказать привет"}, &commandResponse{})
+	resp := messagesResponse{}
+	if code := apiRequest(t, "GET", sessions[1]+"/messages", nil, &resp); code != http.StatusOK {
+		t.Fatalf("messages: status %d", code)
+	}
+	if !reflect.DeepEqual(resp.Messages, []string{"анна говорит: привет"}) {
+		t.Errorf("got messages %q", resp.Messages)
+	}
+
+	apiRequest(t, "POST", sessions[0]+"/commands", commandRequest{"сказать ещё раз"}, &commandResponse{})
+	cmd := commandResponse{}
+	apiRequest(t, "POST", sessions[1]+"/commands", commandRequest{"осмотреться"}, &cmd)
+	if !reflect.DeepEqual(cmd.Messages, []string{"анна говорит: ещё раз"}) {
+		t.Errorf("got messages %q with command", cmd.Messages)
+	}
+	if code := apiRequest(t, "GET", sessions[1]+"/messages", nil, &resp); code != http.StatusOK || len(resp.Messages) != 0 {
+		t.Errorf("messages were delivered twice: %q", resp.Messages)
+	}
+}
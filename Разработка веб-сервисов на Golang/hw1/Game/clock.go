@@ -0,0 +1,297 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// --- Игровое время ---
+//
+// Время в мире измеряется ходами. Каждая команда любого игрока продвигает
+// часы, после чего срабатывают все события расписания, чьё время подошло.
+// Часы подменяются через World.SetClock: в тестах удобен ManualClock,
+// на сервере — WallClock, у которого ходы идут сами.
+
+var errBadSchedule = errors.New("некорректное расписание")
+
+// Clock — игровые часы
+type Clock interface {
+	Now() int  // текущий ход
+	Tick() int // команда игрока: продвинуть часы, если они идут по командам, и вернуть ход
+	Set(t int) // восстановить время, например из сохранения
+}
+
+// TickClock — каждая команда занимает один ход. Часы мира по умолчанию.
+type TickClock struct {
+	t int
+}
+
+func (c *TickClock) Now() int  { return c.t }
+func (c *TickClock) Tick() int { c.t++; return c.t }
+func (c *TickClock) Set(t int) { c.t = t }
+
+// ManualClock стоит на месте, пока его не переведут: для тестов
+type ManualClock struct {
+	T int
+}
+
+func (c *ManualClock) Now() int  { return c.T }
+func (c *ManualClock) Tick() int { return c.T }
+func (c *ManualClock) Set(t int) { c.T = t }
+
+// Advance переводит часы на n ходов вперёд
+func (c *ManualClock) Advance(n int) { c.T += n }
+
+// WallClock отсчитывает ход каждые Period настоящего времени, команды на часы не влияют
+type WallClock struct {
+	Period time.Duration
+	Since  func(time.Time) time.Duration // по умолчанию time.Since
+
+	once  sync.Once
+	start time.Time
+	base  int
+}
+
+func (c *WallClock) init() {
+	c.once.Do(func() {
+		c.start = time.Now()
+		if c.Since == nil {
+			c.Since = time.Since
+		}
+	})
+}
+
+func (c *WallClock) Now() int {
+	c.init()
+	return c.base + int(c.Since(c.start)/c.Period)
+}
+
+func (c *WallClock) Tick() int { return c.Now() }
+
+func (c *WallClock) Set(t int) {
+	c.init()
+	c.base = t - int(c.Since(c.start)/c.Period)
+}
+
+// SetClock заменяет часы мира, текущее время переносится в новые часы
+func (w *World) SetClock(c Clock) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	c.Set(w.clock.Now())
+	w.clock = c
+}
+
+// --- Время суток ---
+
+// Period — время суток, начинается с хода From от начала игровых суток
+type Period struct {
+	name string
+	from int
+}
+
+// ClockDef задаёт игровые сутки
+type ClockDef struct {
+	DayLength int         `json:"dayLength"`       // ходов в сутках
+	Start     int         `json:"start,omitempty"` // какой ход суток идёт в начале игры
+	Periods   []PeriodDef `json:"periods"`
+}
+
+// PeriodDef описывает время суток
+type PeriodDef struct {
+	Name string `json:"name"`
+	From int    `json:"from"`
+}
+
+// timeOfDay — время суток на текущем ходу, "" если сутки не заданы
+func (w *World) timeOfDay() string {
+	if w.dayLength == 0 || len(w.periods) == 0 {
+		return ""
+	}
+	t := (w.clock.Now() + w.dayStart) % w.dayLength
+	name := w.periods[len(w.periods)-1].name // до первого периода длится последний
+	for _, pr := range w.periods {
+		if pr.from <= t {
+			name = pr.name
+		}
+	}
+	return name
+}
+
+// --- Расписание ---
+
+// Scheduled — событие расписания: срабатывает на ходу at, а если задан
+// every — то и каждые every ходов после него
+type Scheduled struct {
+	at, every int
+	room      *Room
+	action    *Trigger // say, unlock, lock, spawn, describe — как у триггеров
+}
+
+// lastDue — последний ход из (from, to], на который приходится событие
+func (s *Scheduled) lastDue(from, to int) (int, bool) {
+	if to < s.at {
+		return 0, false
+	}
+	t := s.at
+	if s.every > 0 {
+		t += (to - s.at) / s.every * s.every
+	}
+	return t, t > from
+}
+
+// ScheduleDef описывает событие расписания в файле мира
+type ScheduleDef struct {
+	At       int    `json:"at"`
+	Every    int    `json:"every,omitempty"`
+	Room     string `json:"room,omitempty"` // комната для spawn, describe и say; для say пусто — все комнаты
+	Say      string `json:"say,omitempty"`
+	Unlock   string `json:"unlock,omitempty"`
+	Lock     string `json:"lock,omitempty"`
+	Spawn    string `json:"spawn,omitempty"`
+	Describe string `json:"describe,omitempty"`
+}
+
+// advance продвигает часы и выполняет события, время которых подошло.
+// Если с прошлой команды событие должно было повториться несколько раз,
+// оно выполняется один раз, за последний повтор: действия событий
+// не накапливаются, а игроку незачем слышать одно и то же.
+// Возвращает тексты, которые должен увидеть игрок p, остальным игрокам
+// они уходят в очередь сообщений. Вызывается под w.mu.
+func (w *World) advance(p *Player) []string {
+	now := w.clock.Tick()
+	type dueEvent struct {
+		t int
+		s *Scheduled
+	}
+	var due []dueEvent
+	for _, s := range w.schedule {
+		if t, ok := s.lastDue(w.lastTick, now); ok {
+			due = append(due, dueEvent{t, s})
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].t < due[j].t })
+
+	var msgs []string
+	for _, d := range due {
+		s := d.s
+		w.fire(s.action, &Event{Room: s.room})
+		if s.action.say == "" {
+			continue
+		}
+		w.tell(s.room, p, "%s", s.action.say)
+		if s.room == nil || s.room == p.room {
+			msgs = append(msgs, s.action.say)
+		}
+	}
+	if now > w.lastTick {
+		w.lastTick = now
+	}
+	return msgs
+}
+
+// currentDescription — описание комнаты с учётом времени суток
+func (r *Room) currentDescription(w *World) string {
+	if d, ok := r.byTime[w.timeOfDay()]; ok {
+		return d
+	}
+	return r.description
+}
+
+func (w *World) handleTime(p *Player, _ []string) string {
+	if tod := w.timeOfDay(); tod != "" {
+		return p.tr("сейчас %s, ход %d", tod, w.clock.Now())
+	}
+	return p.tr("ход %d", w.clock.Now())
+}
+
+// buildClock настраивает часы, сутки и расписание мира
+func (w *World) buildClock(d *WorldDef) {
+	w.clock = &TickClock{}
+	if d.Clock != nil {
+		w.dayLength = d.Clock.DayLength
+		w.dayStart = d.Clock.Start
+		for _, pd := range d.Clock.Periods {
+			w.periods = append(w.periods, Period{name: pd.Name, from: pd.From})
+		}
+		sort.Slice(w.periods, func(i, j int) bool { return w.periods[i].from < w.periods[j].from })
+	}
+	for _, sd := range d.Schedule {
+		w.schedule = append(w.schedule, &Scheduled{
+			at:    sd.At,
+			every: sd.Every,
+			room:  w.rooms[sd.Room],
+			action: &Trigger{
+				say:     sd.Say,
+				unlock:  sd.Unlock,
+				lock:    sd.Lock,
+				spawn:   sd.Spawn,
+				setDesc: sd.Describe,
+			},
+		})
+	}
+}
+
+// validateClock проверяет сутки, описания по времени суток и расписание
+func (d *WorldDef) validateClock(rooms map[string]*RoomDef) []error {
+	periods, errs := d.validatePeriods()
+	for _, rd := range d.Rooms {
+		for name := range rd.ByTime {
+			if !periods[name] {
+				errs = append(errs, fmt.Errorf("комната %q: %w: нет времени суток %q", rd.Name, errBadSchedule, name))
+			}
+		}
+	}
+	for i, sd := range d.Schedule {
+		errs = append(errs, d.validateScheduled(fmt.Sprintf("расписание #%d", i+1), sd, rooms)...)
+	}
+	return errs
+}
+
+// validatePeriods проверяет сутки и возвращает имена времён суток
+func (d *WorldDef) validatePeriods() (map[string]bool, []error) {
+	var errs []error
+	periods := make(map[string]bool)
+	c := d.Clock
+	if c == nil {
+		return periods, nil
+	}
+	if c.DayLength <= 0 {
+		errs = append(errs, fmt.Errorf("%w: длина суток должна быть больше нуля", errBadSchedule))
+	}
+	for _, pd := range c.Periods {
+		if pd.Name == "" || periods[pd.Name] {
+			errs = append(errs, fmt.Errorf("%w: пустое или повторное время суток %q", errBadSchedule, pd.Name))
+		}
+		if pd.From < 0 || (c.DayLength > 0 && pd.From >= c.DayLength) {
+			errs = append(errs, fmt.Errorf("%w: время суток %q начинается вне суток", errBadSchedule, pd.Name))
+		}
+		periods[pd.Name] = true
+	}
+	return periods, errs
+}
+
+// validateScheduled проверяет одно событие расписания
+func (d *WorldDef) validateScheduled(prefix string, sd ScheduleDef, rooms map[string]*RoomDef) []error {
+	var errs []error
+	if sd.At < 0 || sd.Every < 0 {
+		errs = append(errs, fmt.Errorf("%s: %w: отрицательное время", prefix, errBadSchedule))
+	}
+	if _, ok := rooms[sd.Room]; sd.Room != "" && !ok {
+		errs = append(errs, fmt.Errorf("%s: %w: нет комнаты %q", prefix, errBadSchedule, sd.Room))
+	}
+	if sd.Room == "" && (sd.Spawn != "" || sd.Describe != "") {
+		errs = append(errs, fmt.Errorf("%s: %w: spawn и describe требуют комнату", prefix, errBadSchedule))
+	}
+	for _, ref := range []string{sd.Unlock, sd.Lock} {
+		if ref != "" && !d.hasPathRef(ref) {
+			errs = append(errs, fmt.Errorf("%s: %w: нет выхода %q", prefix, errBadSchedule, ref))
+		}
+	}
+	if sd.Spawn != "" && !d.hasItem(sd.Spawn) {
+		errs = append(errs, fmt.Errorf("%s: %w: нет предмета %q", prefix, errBadSchedule, sd.Spawn))
+	}
+	return errs
+}
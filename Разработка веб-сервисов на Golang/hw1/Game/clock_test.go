@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const clockTestWorld = `{
+	"start": "холл",
+	"clock": {"dayLength": 10, "periods": [{"name": "утро", "from": 0}, {"name": "вечер", "from": 5}]},
+	"items": ["газета"],
+	"schedule": [
+		{"at": 2, "room": "холл", "say": "сквозняк захлопнул дверь", "lock": "холл/улица"},
+		{"at": 4, "every": 4, "room": "улица", "describe": "на улице дождь"},
+		{"at": 6, "every": 4, "room": "улица", "describe": "на улице солнце", "say": "выглянуло солнце"},
+		{"at": 5, "room": "холл", "spawn": "газета", "say": "почтальон принёс газету"}
+	],
+	"rooms": [
+		{"name": "холл", "description": "в холле, сейчас {time}", "paths": [{"to": "улица", "lock": {"closable": true}}]},
+		{"name": "улица", "description": "на улице весна", "byTime": {"вечер": "на улице темнеет"}, "paths": [{"to": "холл"}]}
+	]
+}`
+
+func TestTickClockAdvancesPerCommand(t *testing.T) {
+	if err := initGameFrom(strings.NewReader(clockTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	cases := []gameCase{
+		{1, "время", "сейчас утро, ход 1"},
+		{2, "осмотреться", "в холле, сейчас утро. можно пройти - улица. сквозняк захлопнул дверь"},
+		{3, "идти улица", "путь заблокирован"},
+		{4, "время", "сейчас утро, ход 4"},
+		{5, "осмотреться", "в холле, сейчас вечер, на полу: газета. можно пройти - улица. почтальон принёс газету"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
+
+func TestManualClock(t *testing.T) {
+	if err := initGameFrom(strings.NewReader(clockTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+	clock := &ManualClock{}
+	world.SetClock(clock)
+
+	steps := []struct {
+		advance int
+		command string
+		answer  string
+	}{
+		{0, "идти улица", "на улице весна. можно пройти - холл"},
+		{0, "время", "сейчас утро, ход 0"}, // часы стоят, пока их не переведут
+		{3, "осмотреться", "на улице весна. можно пройти - холл"},
+		{1, "осмотреться", "на улице дождь. можно пройти - холл"},
+		{2, "осмотреться", "на улице темнеет. можно пройти - холл. выглянуло солнце"}, // вечером действует byTime
+		{6, "осмотреться", "на улице дождь. можно пройти - холл. выглянуло солнце"},   // утро следующих суток, пропущенные события выполнены
+		{0, "осмотреться", "на улице дождь. можно пройти - холл"},
+		{0, "идти холл", "в холле, сейчас утро. можно пройти - улица"},
+		{0, "осмотреться", "в холле, сейчас утро, на полу: газета. можно пройти - улица"},
+		{0, "идти улица", "путь заблокирован"}, // дверь захлопнулась, пока игрок был на улице
+	}
+	for i, step := range steps {
+		clock.Advance(step.advance)
+		if answer := handleCommand(step.command); answer != step.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", i+1, step.command, answer, step.answer)
+		}
+	}
+}
+
+func TestScheduledSayReachesPlayers(t *testing.T) {
+	if err := initGameFrom(strings.NewReader(clockTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+	clock := &ManualClock{}
+	world.SetClock(clock)
+	anna, err := world.Join("анна")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	boris, err := world.Join("борис")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	boris.Handle("идти улица")
+	anna.Messages()
+
+	// события всех ходов выполняются по команде анны, борис слышит только то, что на улице
+	clock.Advance(6)
+	answer := anna.Handle("время")
+	if want := "сейчас вечер, ход 6. сквозняк захлопнул дверь. почтальон принёс газету"; answer != want {
+		t.Errorf("anna: got %q, want %q", answer, want)
+	}
+	if heard := boris.Messages(); !reflect.DeepEqual(heard, []string{"выглянуло солнце"}) {
+		t.Errorf("boris heard %q", heard)
+	}
+	if heard := anna.Messages(); heard != nil {
+		t.Errorf("anna heard %q in her queue", heard)
+	}
+}
+
+func TestScheduleAfterLongPause(t *testing.T) {
+	src := `{
+		"start": "холл",
+		"schedule": [
+			{"at": 1, "every": 1, "room": "холл", "say": "часы тикают"},
+			{"at": 3, "every": 7, "lock": "холл/улица"},
+			{"at": 5, "every": 7, "unlock": "холл/улица"}
+		],
+		"rooms": [
+			{"name": "холл", "description": "в холле", "paths": [{"to": "улица", "lock": {"closable": true}}]},
+			{"name": "улица", "description": "на улице", "paths": [{"to": "холл"}]}
+		]
+	}`
+	if err := initGameFrom(strings.NewReader(src)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+	clock := &ManualClock{}
+	world.SetClock(clock)
+
+	// миллион ходов без команд: каждое событие выполняется один раз, за свой последний повтор
+	clock.Advance(1_000_000) // последняя блокировка на ходу 999_995, последнее открытие на 999_997
+	if answer, want := handleCommand("идти улица"), "на улице. можно пройти - холл. часы тикают"; answer != want {
+		t.Errorf("got %q, want %q", answer, want)
+	}
+	clock.Advance(3) // блокировка на ходу 1_000_002, тиканье в холле с улицы не слышно
+	if answer, want := handleCommand("идти холл"), "в холле. можно пройти - улица"; answer != want {
+		t.Errorf("got %q, want %q", answer, want)
+	}
+	if answer, want := handleCommand("идти улица"), "путь заблокирован"; answer != want {
+		t.Errorf("got %q, want %q", answer, want)
+	}
+}
+
+func TestClockSnapshot(t *testing.T) {
+	if err := initGameFrom(strings.NewReader(clockTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+	for i := 0; i < 3; i++ {
+		handleCommand("осмотреться")
+	}
+	buf := &bytes.Buffer{}
+	if err := world.Save(buf); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := initGameFrom(strings.NewReader(clockTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err := world.Load(buf); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	// событие второго хода уже выполнено и не повторяется
+	if answer := handleCommand("время"); answer != "сейчас утро, ход 4" {
+		t.Errorf("clock was not restored: %q", answer)
+	}
+}
+
+func TestWallClock(t *testing.T) {
+	elapsed := time.Duration(0)
+	c := &WallClock{Period: time.Minute, Since: func(time.Time) time.Duration { return elapsed }}
+	if c.Tick() != 0 {
+		t.Fatalf("expected turn 0")
+	}
+	elapsed = 150 * time.Second
+	if c.Tick() != 2 || c.Now() != 2 {
+		t.Fatalf("expected turn 2, got %d", c.Now())
+	}
+	c.Set(10)
+	elapsed += time.Minute
+	if c.Now() != 11 {
+		t.Fatalf("expected turn 11, got %d", c.Now())
+	}
+}
+
+func TestClockValidation(t *testing.T) {
+	cases := []string{
+		`{"start": "а", "clock": {"dayLength": 0, "periods": []}, "rooms": [{"name": "а"}]}`,
+		`{"start": "а", "clock": {"dayLength": 4, "periods": [{"name": "ночь", "from": 4}]}, "rooms": [{"name": "а"}]}`,
+		`{"start": "а", "rooms": [{"name": "а", "byTime": {"ночь": "темно"}}]}`,
+		`{"start": "а", "schedule": [{"at": 1, "room": "б"}], "rooms": [{"name": "а"}]}`,
+		`{"start": "а", "schedule": [{"at": 1, "describe": "x"}], "rooms": [{"name": "а"}]}`,
+		`{"start": "а", "schedule": [{"at": -1, "say": "x"}], "rooms": [{"name": "а"}]}`,
+		`{"start": "а", "schedule": [{"at": 1, "unlock": "а/б"}], "rooms": [{"name": "а"}]}`,
+	}
+	for i, src := range cases {
+		def, err := parseWorldDef(strings.NewReader(src))
+		if err != nil {
+			t.Fatalf("case %d: parse: %v", i+1, err)
+		}
+		if err := def.validate(); !errors.Is(err, errBadSchedule) {
+			t.Errorf("case %d: expected errBadSchedule, got %v", i+1, err)
+		}
+	}
+}
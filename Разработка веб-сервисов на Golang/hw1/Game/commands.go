@@ -0,0 +1,189 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// --- Реестр команд ---
+
+var errDuplicateCommand = errors.New("команда уже зарегистрирована")
+
+// Command описывает одну команду игры
+type Command struct {
+	Name    string
+	Aliases []string
+	Args    int    // сколько аргументов нужно как минимум
+	Usage   string // ответ, если аргументов не хватает, например "куда идти?"
+	Help    string // как команда выглядит в списке "помощь", по умолчанию Name
+	Raw     bool   // аргументы не разбираются как названия предметов и мест
+	Text    bool   // аргументы — свободный текст, регистр сохраняется
+	Meta    bool   // команда работает с историей и сама в неё не записывается
+	// Local — команда работает с файлами на машине сервера, поэтому доступна
+	// только игроку по умолчанию, если мир не разрешает её всем (World.remoteSaves)
+	Local bool
+	// ReadOnly — команда ничего не меняет (кроме того, что меняют триггеры),
+	// снимок мира до и после неё не делается
+	ReadOnly bool
+	Run      func(w *World, p *Player, args []string) string
+}
+
+// commandRegistry ищет команды по имени и синонимам.
+// Один реестр есть у мира, свой (необязательный) — у каждой комнаты.
+type commandRegistry struct {
+	byName map[string]*Command
+	order  []*Command // порядок регистрации, в нём команды выводит "помощь"
+}
+
+func newCommandRegistry() *commandRegistry {
+	return &commandRegistry{byName: make(map[string]*Command)}
+}
+
+// Register добавляет команду; имена и синонимы не должны пересекаться с уже известными
+func (cr *commandRegistry) Register(c *Command) error {
+	names := append([]string{c.Name}, c.Aliases...)
+	for _, name := range names {
+		if _, ok := cr.byName[name]; ok {
+			return fmt.Errorf("%w: %s", errDuplicateCommand, name)
+		}
+	}
+	for _, name := range names {
+		cr.byName[name] = c
+	}
+	cr.order = append(cr.order, c)
+	return nil
+}
+
+// RegisterCommand добавляет команду, доступную только в этой комнате
+func (r *Room) RegisterCommand(c *Command) error {
+	if r.commands == nil {
+		r.commands = newCommandRegistry()
+	}
+	return r.commands.Register(c)
+}
+
+func (cr *commandRegistry) lookup(name string) (*Command, bool) {
+	if cr == nil {
+		return nil, false
+	}
+	c, ok := cr.byName[name]
+	return c, ok
+}
+
+// help — как команда выглядит в списке "помощь" на языке l
+func (c *Command) help(l *Locale) string {
+	if c.Help != "" {
+		return l.tr(c.Help)
+	}
+	return l.verb(c.Name)
+}
+
+// defaultCommands — команды, которые есть в любом мире
+func defaultCommands() *commandRegistry {
+	cr := newCommandRegistry()
+	for _, c := range []*Command{
+		{Name: "осмотреться", Aliases: []string{"осмотрись", "оглядеться", "оглядись"}, ReadOnly: true, Run: (*World).handleLook},
+		{Name: "идти", Aliases: []string{"иди", "пойти", "пойди"}, Args: 1, Usage: "куда идти?", Help: "идти <куда>", Run: (*World).handleGo},
+		{Name: "взять", Aliases: []string{"возьми"}, Args: 1, Usage: "что взять?", Help: "взять <что>", Run: (*World).handleTake},
+		{Name: "надеть", Aliases: []string{"надень"}, Args: 1, Usage: "что надеть?", Help: "надеть <что>", Run: (*World).handleWear},
+		{Name: "выложить", Aliases: []string{"выложи", "положить", "положи"}, Args: 1, Usage: "что выложить?", Help: "выложить <что>", Run: (*World).handleDrop},
+		{Name: "инвентарь", Aliases: []string{"инв"}, ReadOnly: true, Run: (*World).handleInventory},
+		{Name: "осмотреть", Aliases: []string{"осмотри"}, Args: 1, Usage: "что осмотреть?", Help: "осмотреть <что>", ReadOnly: true, Run: (*World).handleExamine},
+		{Name: "задания", Aliases: []string{"задачи"}, ReadOnly: true, Run: (*World).handleQuests},
+		{Name: "применить", Aliases: []string{"примени", "использовать", "используй"}, Args: 2, Usage: "что и к чему применить?", Help: "применить <что> <к чему>", Run: (*World).handleUse},
+		{Name: "ввести", Aliases: []string{"введи", "набрать", "набери"}, Args: 2, Usage: "какой код и куда ввести?", Help: "ввести <код> <куда>", Run: (*World).handleCode},
+		{Name: "открыть", Aliases: []string{"открой"}, Args: 1, Usage: "что открыть?", Help: "открыть <что>", Run: (*World).handleOpen},
+		{Name: "закрыть", Aliases: []string{"закрой", "запереть", "запри"}, Args: 1, Usage: "что закрыть?", Help: "закрыть <что>", Run: (*World).handleClose},
+		{Name: "поговорить", Aliases: []string{"говорить", "заговорить", "поговори"}, Args: 1, Usage: "с кем поговорить?", Help: "поговорить <с кем>", Run: (*World).handleTalk},
+		{Name: "ответить", Aliases: []string{"ответ", "ответь"}, Args: 1, Usage: "что ответить?", Help: "ответить <номер>", Run: (*World).handleAnswer},
+		{Name: "сказать", Aliases: []string{"скажи"}, Args: 1, Usage: "что сказать?", Help: "сказать <текст>", Text: true, ReadOnly: true, Run: (*World).handleSay},
+		{Name: "крикнуть", Aliases: []string{"крикни", "кричать"}, Args: 1, Usage: "что крикнуть?", Help: "крикнуть <текст>", Text: true, ReadOnly: true, Run: (*World).handleShout},
+		{Name: "карта", ReadOnly: true, Run: (*World).handleMap},
+		{Name: "время", Aliases: []string{"часы"}, ReadOnly: true, Run: (*World).handleTime},
+		{Name: "отменить", Aliases: []string{"отмени", "отмена"}, Meta: true, Run: (*World).handleUndo},
+		{Name: "повторить", Aliases: []string{"повтори"}, Meta: true, Run: (*World).handleRedo},
+		{Name: "история", Help: "история [имя]", Raw: true, Meta: true, Local: true, Run: (*World).handleHistory},
+		{Name: "сохранить", Help: "сохранить [имя]", Raw: true, Local: true, Run: (*World).handleSave},
+		{Name: "загрузить", Help: "загрузить [имя]", Raw: true, Local: true, Run: (*World).handleLoad},
+		{Name: "язык", Help: "язык [ru|en]", Raw: true, Run: (*World).handleLocale},
+		{Name: "помощь", Aliases: []string{"справка"}, ReadOnly: true, Run: (*World).handleHelp},
+	} {
+		if err := cr.Register(c); err != nil {
+			panic(err)
+		}
+	}
+	return cr
+}
+
+// dispatch находит команду сначала среди команд комнаты, затем среди команд мира
+func (w *World) dispatch(p *Player, command string) string {
+	parts := strings.Fields(strings.ToLower(command))
+	if len(parts) == 0 {
+		return p.tr(UnknownCommandMsg)
+	}
+	parts[0] = p.lang().command(parts[0])
+	c, ok := w.lookup(p, parts[0])
+	if !ok {
+		if names := w.suggest(p, parts[0]); len(names) > 0 {
+			return p.tr("%s, возможно, вы имели в виду: %s", p.tr(UnknownCommandMsg), strings.Join(names, ", "))
+		}
+		return p.tr(UnknownCommandMsg)
+	}
+	if !w.allowed(p, c) {
+		return p.tr("команда доступна только в локальной игре")
+	}
+	args := parts[1:]
+	switch {
+	case c.Text:
+		args = strings.Fields(command)[1:]
+	case !c.Raw:
+		args = w.parseArgs(p, args)
+	}
+	if len(args) < c.Args {
+		return p.tr(c.Usage)
+	}
+	if c.Meta || c.ReadOnly {
+		return c.Run(w, p, args)
+	}
+	before := w.snapshot()
+	answer := c.Run(w, p, args)
+	w.record(p, command, before)
+	return answer
+}
+
+// lookup находит команду по имени на языке мира: сначала среди команд
+// комнаты игрока, затем среди общих
+func (w *World) lookup(p *Player, name string) (*Command, bool) {
+	if c, ok := p.room.commands.lookup(name); ok {
+		return c, true
+	}
+	return w.commands.lookup(name)
+}
+
+// allowed сообщает, может ли игрок p выполнить команду c
+func (w *World) allowed(p *Player, c *Command) bool {
+	return !c.Local || w.remoteSaves || p.id == defaultPlayerID
+}
+
+// handleHelp перечисляет команды, доступные игроку в текущей комнате
+func (w *World) handleHelp(p *Player, _ []string) string {
+	list := []string{}
+	seen := map[*Command]bool{}
+	for _, cr := range []*commandRegistry{p.room.commands, w.commands} {
+		if cr == nil {
+			continue
+		}
+		for _, c := range cr.order {
+			// команда комнаты перекрывает одноимённую команду мира
+			if other, ok := p.room.commands.lookup(c.Name); ok && other != c {
+				continue
+			}
+			if !seen[c] && w.allowed(p, c) {
+				seen[c] = true
+				list = append(list, c.help(p.lang()))
+			}
+		}
+	}
+	return p.tr("доступные команды: %s", strings.Join(list, ", "))
+}
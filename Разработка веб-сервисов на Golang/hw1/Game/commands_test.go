@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHelpListsRegisteredCommands(t *testing.T) {
+	initGame()
+	want := "доступные команды: осмотреться, идти <куда>, взять <что>, надеть <что>, выложить <что>, инвентарь, осмотреть <что>, задания, применить <что> <к чему>, ввести <код> <куда>, открыть <что>, закрыть <что>, поговорить <с кем>, ответить <номер>, сказать <текст>, крикнуть <текст>, карта, время, отменить, повторить, история [имя], сохранить [имя], загрузить [имя], язык [ru|en], помощь"
+	if answer := handleCommand("помощь"); answer != want {
+		t.Errorf("got %q, want %q", answer, want)
+	}
+	if answer := handleCommand("справка"); answer != want {
+		t.Errorf("alias: got %q, want %q", answer, want)
+	}
+}
+
+func TestRoomLocalCommands(t *testing.T) {
+	initGame()
+	kitchen := world.rooms["кухня"]
+	err := kitchen.RegisterCommand(&Command{
+		Name:  "выпить",
+		Args:  1,
+		Usage: "что выпить?",
+		Help:  "выпить <что>",
+		Run: func(w *World, p *Player, args []string) string {
+			if p.room.items[args[0]] == nil {
+				return "нет такого"
+			}
+			delete(p.room.items, args[0])
+			return "вы выпили: " + args[0]
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err := kitchen.RegisterCommand(&Command{Name: "пить", Aliases: []string{"выпить"}}); !errors.Is(err, errDuplicateCommand) {
+		t.Errorf("expected errDuplicateCommand, got %v", err)
+	}
+
+	cases := []gameCase{
+		{1, "выпить", "что выпить?"},
+		{2, "выпить чай", "вы выпили: чай"},
+		{3, "осмотреться", "ты находишься на кухне, на столе: ничего, надо собрать рюкзак и идти в универ. можно пройти - коридор"},
+		{4, "помощь", "доступные команды: выпить <что>, осмотреться, идти <куда>, взять <что>, надеть <что>, выложить <что>, инвентарь, осмотреть <что>, задания, применить <что> <к чему>, ввести <код> <куда>, открыть <что>, закрыть <что>, поговорить <с кем>, ответить <номер>, сказать <текст>, крикнуть <текст>, карта, время, отменить, повторить, история [имя], сохранить [имя], загрузить [имя], язык [ru|en], помощь"},
+		{5, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{6, "выпить чай", "неизвестная команда"}, // в коридоре такой команды нет
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// --- Мебель ---
+//
+// Контейнер — то, на чём или в чём лежат предметы комнаты: стол, стул, шкаф.
+// Он часть комнаты, взять его нельзя. Закрытый контейнер прячет содержимое,
+// запертый сначала отпирают, как дверь: ключом или кодом.
+// Предметы не в контейнерах лежат на полу (Room.items).
+
+var errBadContainer = errors.New("некорректная мебель")
+
+// floorWhere — как выводятся предметы, лежащие на полу
+const floorWhere = "на полу"
+
+// Container — мебель в комнате
+type Container struct {
+	name        string
+	description string
+	where       string // как выводится содержимое: "на столе", "в шкафу"
+	closable    bool   // открывается и закрывается
+	closed      bool
+	lock        *Lock // запертый контейнер не открыть
+	items       map[string]*Item
+}
+
+// ContainerDef описывает мебель в комнате
+type ContainerDef struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Where       string    `json:"where"` // "на столе", "в шкафу"
+	Closable    bool      `json:"closable,omitempty"`
+	Closed      bool      `json:"closed,omitempty"`
+	Lock        *LockDef  `json:"lock,omitempty"`
+	Items       []ItemDef `json:"items,omitempty"`
+}
+
+// allItems — предметы комнаты вместе с лежащими в мебели
+func (rd RoomDef) allItems() []ItemDef {
+	items := append([]ItemDef{}, rd.Items...)
+	for _, cd := range rd.Containers {
+		items = append(items, cd.Items...)
+	}
+	return items
+}
+
+// validateContainers проверяет мебель всех комнат
+func (d *WorldDef) validateContainers() []error {
+	var errs []error
+	for _, rd := range d.Rooms {
+		seen := make(map[string]bool, len(rd.Containers))
+		for _, cd := range rd.Containers {
+			where := fmt.Sprintf("комната %q, мебель %q", rd.Name, cd.Name)
+			switch {
+			case cd.Name == "" || cd.Where == "":
+				errs = append(errs, fmt.Errorf("%s: %w", where, errEmptyName))
+			case seen[cd.Name]:
+				errs = append(errs, fmt.Errorf("%s: %w: объявлена повторно", where, errBadContainer))
+			case (cd.Closed || cd.Lock != nil) && !cd.Closable:
+				errs = append(errs, fmt.Errorf("%s: %w: closed и lock требуют closable", where, errBadContainer))
+			}
+			seen[cd.Name] = true
+		}
+	}
+	return errs
+}
+
+// container находит мебель комнаты по имени
+func (r *Room) container(name string) *Container {
+	for _, c := range r.containers {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// find ищет предмет, который игрок видит в комнате: на полу или в открытой мебели
+func (r *Room) find(name string) (*Item, *Container) {
+	if it, ok := r.items[name]; ok {
+		return it, nil
+	}
+	for _, c := range r.containers {
+		if it, ok := c.items[name]; ok && !c.closed {
+			return it, c
+		}
+	}
+	return nil, nil
+}
+
+// visible — все предметы, которые игрок видит в комнате
+func (r *Room) visible() map[string]*Item {
+	items := make(map[string]*Item, len(r.items))
+	for name, it := range r.items {
+		items[name] = it
+	}
+	for _, c := range r.containers {
+		if c.closed {
+			continue
+		}
+		for name, it := range c.items {
+			items[name] = it
+		}
+	}
+	return items
+}
+
+// remove убирает предмет оттуда, где он лежит
+func (r *Room) remove(name string, c *Container) {
+	if c == nil {
+		delete(r.items, name)
+		return
+	}
+	delete(c.items, name)
+}
+
+// contents — видимые предметы по местам: "на столе: ключи, на стуле: рюкзак, на полу: чай"
+func (w *World) contents(p *Player, r *Room) string {
+	groups := []string{}
+	for _, c := range r.containers {
+		if !c.closed && len(c.items) > 0 {
+			groups = append(groups, c.where+": "+strings.Join(sortedKeys(c.items), ", "))
+		}
+	}
+	if len(r.items) > 0 {
+		groups = append(groups, p.tr(floorWhere)+": "+strings.Join(sortedKeys(r.items), ", "))
+	}
+	if len(groups) == 0 {
+		return p.tr("ничего")
+	}
+	return strings.Join(groups, ", ")
+}
+
+// locate находит предмет для "взять" и "надеть": args[0] — что, args[1] — откуда.
+// Если откуда не сказано, ищет на полу и в открытой мебели.
+// Третий результат — ответ игроку, если предмета не достать.
+func (w *World) locate(p *Player, args []string) (*Item, *Container, string) {
+	name := args[0]
+	if len(args) < 2 {
+		if it, c := p.room.find(name); it != nil {
+			return it, c, ""
+		}
+		return nil, nil, p.tr("нет такого")
+	}
+	c := p.room.container(args[1])
+	switch {
+	case c == nil:
+		return nil, nil, p.tr("здесь нет - %s", args[1])
+	case c.closed:
+		return nil, nil, p.tr("закрыто - %s", c.name)
+	case c.items[name] == nil:
+		return nil, nil, p.tr("нет такого")
+	}
+	return c.items[name], c, ""
+}
+
+// --- Команды "открыть" и "закрыть" ---
+
+func (w *World) handleOpen(p *Player, args []string) string {
+	target := args[0]
+	c := p.room.container(target)
+	switch {
+	case c == nil:
+		return p.tr("нет такого")
+	case !c.closable:
+		return p.tr("не открывается - %s", target)
+	case !c.closed:
+		return p.tr("уже открыто - %s", target)
+	case c.lock != nil && c.lock.locked:
+		return p.tr("заперто - %s", target)
+	}
+	c.closed = false
+	answer := p.tr("вы открыли: %s", target)
+	if len(c.items) == 0 {
+		return withEvents(answer, []string{p.tr("там пусто")})
+	}
+	return withEvents(answer, []string{c.where + ": " + strings.Join(sortedKeys(c.items), ", ")})
+}
+
+// closeContainer закрывает мебель. Второй результат false — target не мебель
+// или её надо не закрыть, а запереть: тогда работает handleClose как с замком.
+func (w *World) closeContainer(p *Player, target string) (string, bool) {
+	c := p.room.container(target)
+	if c == nil || !c.closable {
+		return "", false
+	}
+	if !c.closed {
+		c.closed = true
+		return p.tr("вы закрыли: %s", target), true
+	}
+	if c.lock == nil {
+		return p.tr("уже закрыто - %s", target), true
+	}
+	return "", false
+}
+
+// examineContainer описывает мебель для "осмотреть"
+func (w *World) examineContainer(p *Player, c *Container) string {
+	answer := c.description
+	if answer == "" {
+		answer = p.tr("%s - ничего особенного", c.name)
+	}
+	switch {
+	case c.closed:
+		return withEvents(answer, []string{p.tr("закрыто - %s", c.name)})
+	case len(c.items) > 0:
+		return withEvents(answer, []string{c.where + ": " + strings.Join(sortedKeys(c.items), ", ")})
+	}
+	return answer
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestContainers(t *testing.T) {
+	initGame()
+	cases := []gameCase{
+		{1, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{2, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{3, "осмотреться", "на столе: ключи, конспекты, на стуле: рюкзак. можно пройти - коридор"}, // закрытый шкаф содержимое не показывает
+		{4, "надеть рюкзак", "вы надели: рюкзак"},
+		{5, "взять зонт", "нет такого"},
+		{6, "взять зонт из шкафа", "закрыто - шкаф"},
+		{7, "осмотреть шкаф", "старый платяной шкаф. закрыто - шкаф"},
+		{8, "открыть стол", "не открывается - стол"},
+		{9, "открыть диван", "нет такого"},
+		{10, "открыть шкаф", "вы открыли: шкаф. в шкафу: зонт"},
+		{11, "открыть шкаф", "уже открыто - шкаф"},
+		{12, "осмотреться", "на столе: ключи, конспекты, в шкафу: зонт. можно пройти - коридор"},
+		{13, "взять ключи из шкафа", "нет такого"},
+		{14, "взять ключи со стола", "предмет добавлен в инвентарь: ключи"},
+		{15, "взять зонт", "предмет добавлен в инвентарь: зонт"},
+		{16, "осмотреть шкаф", "старый платяной шкаф"},
+		{17, "выложить зонт в диван", "здесь нет - диван"},
+		{18, "выложить зонт в шкаф", "вы выложили: зонт"},
+		{19, "закрыть шкаф", "вы закрыли: шкаф"},
+		{20, "закрыть шкаф", "уже закрыто - шкаф"},
+		{21, "выложить ключи в шкаф", "закрыто - шкаф"},
+		{22, "выложить ключи", "вы выложили: ключи"},
+		{23, "осмотреться", "на столе: конспекты, на полу: ключи. можно пройти - коридор"},
+		{24, "взять конспекты", "предмет добавлен в инвентарь: конспекты"},
+		{25, "взять ключи", "предмет добавлен в инвентарь: ключи"},
+		{26, "осмотреться", "пустая комната. можно пройти - коридор"},
+		{27, "открыть шкаф", "вы открыли: шкаф. в шкафу: зонт"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
+
+func TestLockedContainer(t *testing.T) {
+	src := `{
+		"start": "кабинет",
+		"items": [{"name": "рюкзак", "wearable": true, "capacity": 5}],
+		"rooms": [
+			{
+				"name": "кабинет",
+				"description": "в кабинете",
+				"items": ["рюкзак"],
+				"containers": [
+					{"name": "сейф", "where": "в сейфе", "closable": true, "closed": true, "lock": {"name": "сейф", "locked": true, "code": "42"}, "items": ["деньги"]}
+				]
+			}
+		]
+	}`
+	if err := initGameFrom(strings.NewReader(src)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	cases := []gameCase{
+		{1, "открыть сейф", "заперто - сейф"},
+		{2, "ввести 42 сейф", "открыто"},
+		{3, "открыть сейф", "вы открыли: сейф. в сейфе: деньги"},
+		{4, "осмотреться", "в кабинете, в сейфе: деньги, на полу: рюкзак. можно пройти - "},
+		{5, "закрыть сейф", "вы закрыли: сейф"},
+		{6, "закрыть сейф", "не запирается - сейф"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+	if errs := world.Lint(); errs != nil {
+		t.Errorf("unexpected lint errors: %v", errs)
+	}
+}
+
+func TestContainersValidation(t *testing.T) {
+	src := `{"start": "а", "rooms": [{"name": "а", "containers": [
+		{"name": "стол", "where": "на столе"},
+		{"name": "стол", "where": "на столе"},
+		{"name": "ящик", "where": "в ящике", "closed": true}
+	]}]}`
+	def, err := parseWorldDef(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	errs := def.validateContainers()
+	if len(errs) != 2 || !errors.Is(errs[0], errBadContainer) || !errors.Is(errs[1], errBadContainer) {
+		t.Errorf("got %v, want two errBadContainer", errs)
+	}
+}
+
+func TestContainersSnapshot(t *testing.T) {
+	initGame()
+	for _, cmd := range []string{"идти коридор", "идти комната", "открыть шкаф", "взять зонт", "надеть рюкзак", "взять зонт"} {
+		handleCommand(cmd)
+	}
+	buf := &bytes.Buffer{}
+	if err := world.Save(buf); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	saved := buf.String()
+
+	initGame()
+	if err := world.Load(strings.NewReader(saved)); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if answer := handleCommand("осмотреться"); answer != "на столе: ключи, конспекты. можно пройти - коридор" {
+		t.Errorf("open wardrobe was not restored: %q", answer)
+	}
+	if answer := handleCommand("закрыть шкаф"); answer != "вы закрыли: шкаф" {
+		t.Errorf("unexpected answer %q", answer)
+	}
+
+	// снимок версии 3: мебели нет, всё лежит в комнате
+	initGame()
+	snap := world.snapshot()
+	st := snap.Rooms["комната"]
+	st.Items = []string{"ключи", "конспекты", "рюкзак"}
+	st.Containers = nil
+	snap.Rooms["комната"] = st
+	snap.Version = 3
+	buf.Reset()
+	if err := json.NewEncoder(buf).Encode(snap); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if err := world.Load(buf); err != nil {
+		t.Fatalf("load v3: %v", err)
+	}
+	handleCommand("идти коридор")
+	handleCommand("идти комната")
+	if answer := handleCommand("осмотреться"); answer != "на столе: ключи, конспекты, на стуле: рюкзак. можно пройти - коридор" {
+		t.Errorf("v3 items were not put back into furniture: %q", answer)
+	}
+}
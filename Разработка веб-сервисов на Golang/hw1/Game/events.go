@@ -0,0 +1,288 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// --- События и триггеры ---
+
+// EventKind — вид игрового события
+type EventKind string
+
+const (
+	EventEnter EventKind = "enter" // игрок вошёл в комнату
+	EventLeave EventKind = "leave" // игрок собирается уйти из комнаты
+	EventTake  EventKind = "take"  // игрок берёт или надевает предмет
+	EventUse   EventKind = "use"   // игрок применяет предмет
+	EventLook  EventKind = "look"  // игрок осматривается
+	EventDrop  EventKind = "drop"  // игрок выкладывает предмет
+)
+
+var eventKinds = map[EventKind]bool{
+	EventEnter: true,
+	EventLeave: true,
+	EventTake:  true,
+	EventUse:   true,
+	EventLook:  true,
+	EventDrop:  true,
+}
+
+var (
+	errUnknownEvent = errors.New("неизвестное событие")
+	errBadTrigger   = errors.New("некорректный триггер")
+)
+
+// Event описывает, что произошло. Заполнены только поля, относящиеся к виду события.
+type Event struct {
+	Kind   EventKind
+	Player *Player
+	Room   *Room  // комната, где произошло событие
+	Item   *Item  // take, use, drop
+	Path   *Path  // enter, leave — путь, которым прошёл игрок
+	Target string // use — к чему применяли предмет
+}
+
+// EventHandler — подписчик на события из кода, возвращает текст для игрока или ""
+type EventHandler func(w *World, ev *Event) string
+
+// Trigger — реакция на событие, заданная в описании мира.
+// Триггеры висят на комнатах, предметах и путях.
+type Trigger struct {
+	on      EventKind
+	when    Condition
+	say     string
+	deny    bool   // отменить действие игрока (для leave, take, use, drop)
+	unlock  string // "комната/выход"
+	lock    string // "комната/выход"
+	spawn   string // положить предмет в комнату события
+	setDesc string // новое описание комнаты события
+}
+
+// Condition — условие на состояние игрока и параметры события, пустые поля не проверяются
+type Condition struct {
+	Wears    string `json:"wears,omitempty"`
+	NotWears string `json:"notWears,omitempty"`
+	Has      string `json:"has,omitempty"`
+	NotHas   string `json:"notHas,omitempty"`
+	Item     string `json:"item,omitempty"`   // событие касается этого предмета
+	Target   string `json:"target,omitempty"` // use: цель применения
+}
+
+func (c Condition) match(ev *Event) bool {
+	p := ev.Player
+	switch {
+	case c.Wears != "" && !p.wears(c.Wears):
+		return false
+	case c.NotWears != "" && p.wears(c.NotWears):
+		return false
+	case c.Has != "" && p.inventory[c.Has] == nil:
+		return false
+	case c.NotHas != "" && p.inventory[c.NotHas] != nil:
+		return false
+	case c.Item != "" && (ev.Item == nil || ev.Item.name != c.Item):
+		return false
+	case c.Target != "" && ev.Target != c.Target:
+		return false
+	}
+	return true
+}
+
+// TriggerDef описывает триггер в файле мира
+type TriggerDef struct {
+	On       EventKind `json:"on"`
+	If       Condition `json:"if,omitempty"`
+	Say      string    `json:"say,omitempty"`
+	Deny     bool      `json:"deny,omitempty"`
+	Unlock   string    `json:"unlock,omitempty"`
+	Lock     string    `json:"lock,omitempty"`
+	Spawn    string    `json:"spawn,omitempty"`
+	Describe string    `json:"describe,omitempty"`
+}
+
+func (td TriggerDef) trigger() *Trigger {
+	return &Trigger{
+		on:      td.On,
+		when:    td.If,
+		say:     td.Say,
+		deny:    td.Deny,
+		unlock:  td.Unlock,
+		lock:    td.Lock,
+		spawn:   td.Spawn,
+		setDesc: td.Describe,
+	}
+}
+
+func buildTriggers(defs []TriggerDef) []*Trigger {
+	if len(defs) == 0 {
+		return nil
+	}
+	triggers := make([]*Trigger, 0, len(defs))
+	for _, td := range defs {
+		triggers = append(triggers, td.trigger())
+	}
+	return triggers
+}
+
+// On подписывает обработчик из кода на события вида kind.
+// Обработчик, который меняет мир, должен увеличить w.version сам,
+// как это делает fire.
+func (w *World) On(kind EventKind, h EventHandler) {
+	if w.handlers == nil {
+		w.handlers = make(map[EventKind][]EventHandler)
+	}
+	w.handlers[kind] = append(w.handlers[kind], h)
+}
+
+// emit запускает триггеры комнаты, предмета и пути события, а затем подписчиков.
+// Возвращает тексты для игрока и признак того, что действие отменено.
+func (w *World) emit(ev *Event) (msgs []string, denied bool) {
+	var triggers []*Trigger
+	if ev.Room != nil {
+		triggers = append(triggers, ev.Room.triggers...)
+	}
+	if ev.Item != nil {
+		triggers = append(triggers, ev.Item.triggers...)
+	}
+	if ev.Path != nil {
+		triggers = append(triggers, ev.Path.triggers...)
+	}
+	for _, t := range triggers {
+		if t.on != ev.Kind || !t.when.match(ev) {
+			continue
+		}
+		w.fire(t, ev)
+		if t.say != "" {
+			msgs = append(msgs, t.say)
+			w.fired = append(w.fired, ResultEvent{Source: SourceTrigger, Text: t.say})
+		}
+		denied = denied || t.deny
+	}
+	for _, h := range w.handlers[ev.Kind] {
+		if msg := h(w, ev); msg != "" {
+			msgs = append(msgs, msg)
+			w.fired = append(w.fired, ResultEvent{Source: SourceTrigger, Text: msg})
+		}
+	}
+	return msgs, denied
+}
+
+// fire выполняет действия триггера. Если мир изменился, растёт его версия:
+// триггер мог сработать по расписанию или в команде без снимка
+// (Command.ReadOnly), и отмена более ранней команды не должна это затереть.
+func (w *World) fire(t *Trigger, ev *Event) {
+	changed := false
+	if t.unlock != "" {
+		path := w.pathByRef(t.unlock)
+		changed = changed || path.locked()
+		path.setLocked(false)
+	}
+	if t.lock != "" {
+		path := w.pathByRef(t.lock)
+		changed = changed || !path.locked()
+		path.setLocked(true)
+	}
+	if t.spawn != "" && !w.itemPlaced(t.spawn) {
+		ev.Room.items[t.spawn] = w.items[t.spawn]
+		changed = true
+	}
+	if t.setDesc != "" && ev.Room.description != t.setDesc {
+		ev.Room.description = t.setDesc
+		changed = true
+	}
+	if changed {
+		w.version++
+	}
+}
+
+// pathByRef находит путь по ссылке вида "комната/выход"
+func (w *World) pathByRef(ref string) *Path {
+	room, path, _ := strings.Cut(ref, "/")
+	return w.rooms[room].paths[path]
+}
+
+// itemPlaced сообщает, лежит ли предмет где-нибудь в мире или у игроков
+func (w *World) itemPlaced(name string) bool {
+	for _, r := range w.rooms {
+		if r.items[name] != nil {
+			return true
+		}
+		for _, c := range r.containers {
+			if c.items[name] != nil {
+				return true
+			}
+		}
+	}
+	for _, p := range w.players {
+		if p.inventory[name] != nil || p.worn[name] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// withEvents дописывает к ответу тексты сработавших триггеров
+func withEvents(answer string, msgs []string) string {
+	if len(msgs) == 0 {
+		return answer
+	}
+	return strings.Join(append([]string{answer}, msgs...), ". ")
+}
+
+// validateTriggers проверяет события и ссылки на комнаты, выходы и предметы
+func validateTriggers(where string, defs []TriggerDef, d *WorldDef) []error {
+	var errs []error
+	for i, td := range defs {
+		prefix := fmt.Sprintf("%s, триггер #%d", where, i+1)
+		if !eventKinds[td.On] {
+			errs = append(errs, fmt.Errorf("%s: %w %q", prefix, errUnknownEvent, td.On))
+		}
+		if td.Deny && (td.On == EventEnter || td.On == EventLook) {
+			errs = append(errs, fmt.Errorf("%s: %w: событие %q нельзя отменить", prefix, errBadTrigger, td.On))
+		}
+		for _, ref := range []string{td.Unlock, td.Lock} {
+			if ref != "" && !d.hasPathRef(ref) {
+				errs = append(errs, fmt.Errorf("%s: %w: нет выхода %q", prefix, errBadTrigger, ref))
+			}
+		}
+		if td.Spawn != "" && !d.hasItem(td.Spawn) {
+			errs = append(errs, fmt.Errorf("%s: %w: нет предмета %q", prefix, errBadTrigger, td.Spawn))
+		}
+	}
+	return errs
+}
+
+func (d *WorldDef) hasPathRef(ref string) bool {
+	room, path, ok := strings.Cut(ref, "/")
+	if !ok {
+		return false
+	}
+	for _, rd := range d.Rooms {
+		if rd.Name != room {
+			continue
+		}
+		for _, pd := range rd.Paths {
+			if pd.name() == path {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (d *WorldDef) hasItem(name string) bool {
+	for _, it := range d.Items {
+		if it.Name == name {
+			return true
+		}
+	}
+	for _, rd := range d.Rooms {
+		for _, it := range rd.allItems() {
+			if it.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
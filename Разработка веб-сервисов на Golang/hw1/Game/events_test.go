@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const eventsTestWorld = `{
+	"start": "холл",
+	"items": [
+		{"name": "зонт", "wearable": true, "capacity": 2},
+		{"name": "спички", "triggers": [
+			{"on": "use", "if": {"target": "камин"}, "say": "камин разгорелся", "describe": "в холле тепло", "unlock": "холл/подвал"}
+		]},
+		{"name": "монета"}
+	],
+	"rooms": [
+		{
+			"name": "холл",
+			"description": "в холле холодно",
+			"items": ["зонт", "спички"],
+			"triggers": [
+				{"on": "leave", "if": {"notWears": "зонт"}, "say": "на улице дождь, нужен зонт", "deny": true},
+				{"on": "take", "if": {"item": "спички"}, "say": "из коробка выпала монета", "spawn": "монета"}
+			],
+			"paths": [
+				{"to": "двор", "triggers": [{"on": "enter", "say": "дверь хлопнула за спиной"}]},
+				{"to": "подвал", "locked": true, "lockMsg": "подвал заперт"}
+			]
+		},
+		{"name": "двор", "description": "мокро", "paths": [{"to": "холл"}]},
+		{"name": "подвал", "description": "темно", "paths": [{"to": "холл"}]}
+	]
+}`
+
+func TestRoomItemAndPathTriggers(t *testing.T) {
+	if err := initGameFrom(strings.NewReader(eventsTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	cases := []gameCase{
+		{1, "идти двор", "на улице дождь, нужен зонт"},
+		{2, "надеть зонт", "вы надели: зонт"},
+		{3, "взять спички", "предмет добавлен в инвентарь: спички. из коробка выпала монета"},
+		{4, "осмотреться", "в холле холодно, на полу: монета. можно пройти - двор, подвал"},
+		{5, "идти подвал", "подвал заперт"},
+		{6, "применить спички камин", "камин разгорелся"},
+		{7, "осмотреться", "в холле тепло, на полу: монета. можно пройти - двор, подвал"},
+		{8, "идти двор", "мокро. можно пройти - холл. дверь хлопнула за спиной"},
+		{9, "идти холл", "в холле тепло. можно пройти - двор, подвал"},
+		{10, "взять монета", "предмет добавлен в инвентарь: монета"},
+		{11, "идти подвал", "темно. можно пройти - холл"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+
+	// изменённое триггером описание переживает сохранение
+	buf := &bytes.Buffer{}
+	if err := world.Save(buf); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := initGameFrom(strings.NewReader(eventsTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err := world.Load(buf); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := world.rooms["холл"].description; got != "в холле тепло" {
+		t.Errorf("description was not restored: %q", got)
+	}
+}
+
+func TestEventSubscribers(t *testing.T) {
+	initGame()
+	entered := []string{}
+	world.On(EventEnter, func(w *World, ev *Event) string {
+		entered = append(entered, ev.Room.name)
+		if ev.Room.name == "улица" {
+			return "пахнет весной"
+		}
+		return ""
+	})
+	for _, cmd := range []string{"идти коридор", "идти комната", "надеть рюкзак", "взять ключи", "идти коридор", "применить ключи дверь"} {
+		handleCommand(cmd)
+	}
+	if answer := handleCommand("идти улица"); answer != "на улице весна. можно пройти - домой. пахнет весной" {
+		t.Errorf("unexpected answer %q", answer)
+	}
+	if got := strings.Join(entered, ","); got != "коридор,комната,коридор,улица" {
+		t.Errorf("unexpected enter events: %s", got)
+	}
+}
+
+func TestTriggerValidation(t *testing.T) {
+	src := `{"start": "а", "rooms": [{"name": "а", "triggers": [
+		{"on": "прыжок"},
+		{"on": "enter", "deny": true},
+		{"on": "look", "unlock": "а/нет"},
+		{"on": "look", "spawn": "призрак"}
+	]}]}`
+	def, err := parseWorldDef(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	_, err = newWorld(def)
+	if !errors.Is(err, errUnknownEvent) || !errors.Is(err, errBadTrigger) {
+		t.Fatalf("expected trigger errors, got %v", err)
+	}
+	if n := strings.Count(err.Error(), "триггер #"); n != 4 {
+		t.Errorf("expected 4 trigger errors, got %d: %v", n, err)
+	}
+}
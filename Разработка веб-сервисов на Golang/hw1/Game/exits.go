@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// --- Порядок выходов ---
+
+// Порядок, в котором "можно пройти - ..." перечисляет выходы комнаты.
+// Задаётся для всего мира (WorldDef.ExitOrder) и может быть переопределён
+// в комнате (RoomDef.ExitOrder).
+const (
+	ExitOrderDeclared     = "declared"     // как пути объявлены в комнате, по умолчанию
+	ExitOrderAlphabetical = "alphabetical" // по алфавиту
+	ExitOrderCompass      = "compass"      // по сторонам света, остальные выходы — следом по алфавиту
+)
+
+var errUnknownExitOrder = errors.New("неизвестный порядок выходов")
+
+// compassRank — порядок сторон света по часовой стрелке от севера
+var compassRank = map[string]int{
+	"север":         1,
+	"северо-восток": 2,
+	"восток":        3,
+	"юго-восток":    4,
+	"юг":            5,
+	"юго-запад":     6,
+	"запад":         7,
+	"северо-запад":  8,
+	"вверх":         9,
+	"вниз":          10,
+}
+
+func validExitOrder(order string) bool {
+	switch order {
+	case "", ExitOrderDeclared, ExitOrderAlphabetical, ExitOrderCompass:
+		return true
+	}
+	return false
+}
+
+// exitOrder — порядок выходов комнаты с учётом порядка мира
+func (rd *RoomDef) exitOrder(def string) string {
+	if rd.ExitOrder != "" {
+		return rd.ExitOrder
+	}
+	return def
+}
+
+// exitRank — место выхода среди сторон света: по подсказке направления
+// (PathDef.Dir или имя вроде "север"), 0 — выход не по сторонам света
+func exitRank(pd PathDef) int {
+	if rank, ok := compassRank[pathDir(pd)]; ok {
+		return rank
+	}
+	return compassRank[pd.name()]
+}
+
+// orderExits возвращает имена выходов комнаты в порядке order
+func orderExits(paths []PathDef, order string) []string {
+	names := make([]string, 0, len(paths))
+	rank := make(map[string]int, len(paths))
+	for _, pd := range paths {
+		names = append(names, pd.name())
+		rank[pd.name()] = exitRank(pd)
+	}
+	switch order {
+	case ExitOrderAlphabetical:
+		sort.Strings(names)
+	case ExitOrderCompass:
+		sort.SliceStable(names, func(i, j int) bool {
+			a, b := rank[names[i]], rank[names[j]]
+			switch {
+			case a != 0 && b != 0:
+				return a < b
+			case a != 0 || b != 0:
+				return a != 0 // стороны света раньше прочих выходов
+			}
+			return names[i] < names[j]
+		})
+	}
+	return names
+}
+
+// validateExitOrders проверяет порядок выходов мира и комнат
+func (d *WorldDef) validateExitOrders() []error {
+	var errs []error
+	if !validExitOrder(d.ExitOrder) {
+		errs = append(errs, fmt.Errorf("%w %q", errUnknownExitOrder, d.ExitOrder))
+	}
+	for _, rd := range d.Rooms {
+		if !validExitOrder(rd.ExitOrder) {
+			errs = append(errs, fmt.Errorf("комната %q: %w %q", rd.Name, errUnknownExitOrder, rd.ExitOrder))
+		}
+	}
+	return errs
+}
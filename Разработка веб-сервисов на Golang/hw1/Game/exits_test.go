@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestOrderExits(t *testing.T) {
+	paths := []PathDef{{To: "чердак"}, {Name: "юг", To: "сад"}, {To: "кухня"}, {Name: "север", To: "двор"}, {Name: "вниз", To: "подвал"}}
+	cases := map[string]string{
+		"":                    "чердак, юг, кухня, север, вниз",
+		ExitOrderDeclared:     "чердак, юг, кухня, север, вниз",
+		ExitOrderAlphabetical: "вниз, кухня, север, чердак, юг",
+		ExitOrderCompass:      "север, юг, вниз, кухня, чердак",
+	}
+	for order, want := range cases {
+		if got := strings.Join(orderExits(paths, order), ", "); got != want {
+			t.Errorf("order %q: got %q, want %q", order, got, want)
+		}
+	}
+}
+
+func TestCompassOrderFollowsDir(t *testing.T) {
+	src := `{
+		"start": "холл",
+		"exitOrder": "compass",
+		"rooms": [
+			{"name": "холл", "description": "просторно", "paths": [
+				{"to": "ангар", "dir": "юг"}, {"to": "сад", "dir": "север"}, {"to": "башня", "dir": "восток"}
+			]},
+			{"name": "сад", "description": "тихо", "paths": [{"to": "холл"}]},
+			{"name": "башня", "description": "высоко", "paths": [{"to": "холл"}]},
+			{"name": "ангар", "description": "гулко", "paths": [{"to": "холл"}]}
+		]
+	}`
+	if err := initGameFrom(strings.NewReader(src)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	if answer, want := handleCommand("осмотреться"), "просторно. можно пройти - сад, башня, ангар"; answer != want {
+		t.Errorf("got %q, want %q", answer, want)
+	}
+}
+
+func TestExitOrderFromWorld(t *testing.T) {
+	src := `{
+		"start": "холл",
+		"exitOrder": "alphabetical",
+		"rooms": [
+			{"name": "холл", "description": "просторно", "paths": [{"to": "сад"}, {"to": "библиотека"}, {"to": "башня"}]},
+			{"name": "сад", "description": "тихо", "exitOrder": "compass", "paths": [{"name": "запад", "to": "башня"}, {"name": "север", "to": "холл"}]},
+			{"name": "башня", "description": "высоко", "exitOrder": "declared", "paths": [{"to": "холл"}, {"to": "библиотека"}]},
+			{"name": "библиотека", "description": "пыльно", "paths": [{"to": "холл"}]}
+		]
+	}`
+	if err := initGameFrom(strings.NewReader(src)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	cases := []gameCase{
+		{1, "осмотреться", "просторно. можно пройти - башня, библиотека, сад"},
+		{2, "идти сад", "тихо. можно пройти - север, запад"},
+		{3, "идти запад", "высоко. можно пройти - холл, библиотека"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
+
+func TestUnknownExitOrder(t *testing.T) {
+	src := `{"start": "а", "exitOrder": "random", "rooms": [{"name": "а", "description": "а", "exitOrder": "по кругу"}]}`
+	def, err := parseWorldDef(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	err = def.validate()
+	if !errors.Is(err, errUnknownExitOrder) {
+		t.Fatalf("expected errUnknownExitOrder, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "random") || !strings.Contains(err.Error(), "по кругу") {
+		t.Errorf("expected both bad orders to be reported, got %v", err)
+	}
+}
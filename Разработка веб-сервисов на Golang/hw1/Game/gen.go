@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+)
+
+// --- Генератор миров ---
+//
+// generateWorldDef строит по зерну описание мира — то же, что читается из
+// файла, поэтому сгенерированный мир проверяется validate и играется как
+// любой другой. Одно и то же зерно всегда даёт один и тот же мир.
+//
+// Комнаты растут деревом по клеткам сетки от стартовой, затем между
+// соседними клетками добавляются лишние проходы. Часть проходов дерева
+// запирается, и ключ от каждой двери кладётся туда, куда можно дойти,
+// открыв только двери, выбранные раньше неё. Стартовая комната за дверями
+// не бывает, поэтому у каждого ключа есть хотя бы одно подходящее место.
+
+// GenOptions — параметры генератора
+type GenOptions struct {
+	Seed  uint64
+	Rooms int // сколько комнат, по умолчанию 8
+	Items int // сколько простых предметов разложить, по умолчанию Rooms/2
+	Locks int // сколько дверей запереть, по умолчанию Rooms/3
+}
+
+// genRoomNames — имена комнат, лишние получают номер: "зал-2"
+var genRoomNames = []string{
+	"холл", "библиотека", "кладовка", "галерея", "столовая", "оранжерея",
+	"подвал", "чердак", "мастерская", "спальня", "архив", "часовня",
+	"погреб", "башня", "сад", "кузница", "лаборатория", "зал",
+}
+
+// genDetails — из двух разных деталей складывается описание комнаты
+var genDetails = []string{
+	"пахнет пылью", "тихо", "горит свеча", "сквозит", "скрипят половицы",
+	"темно", "капает вода", "на стенах портреты", "всюду паутина", "тепло",
+	"гуляет эхо", "пол в трещинах",
+}
+
+// genItems — простые предметы, которые раскладываются по комнатам
+var genItems = []ItemDef{
+	{Name: "свеча", Description: "огарок свечи"},
+	{Name: "книга", Description: "книга без обложки"},
+	{Name: "монета", Description: "старая монета"},
+	{Name: "перо", Description: "гусиное перо"},
+	{Name: "кружка", Description: "оловянная кружка"},
+	{Name: "верёвка", Description: "моток верёвки"},
+	{Name: "компас", Description: "компас, стрелка дрожит"},
+	{Name: "письмо", Description: "письмо без подписи"},
+	{Name: "кость", Description: "чья-то кость"},
+	{Name: "зеркальце", Description: "треснувшее зеркальце"},
+}
+
+// genSides — стороны света для проходов дерева по порядку перебора
+var genSides = []string{"север", "восток", "юг", "запад"}
+
+var genOpposite = map[string]string{"север": "юг", "юг": "север", "восток": "запад", "запад": "восток"}
+
+func (o GenOptions) withDefaults() GenOptions {
+	if o.Rooms < 2 {
+		o.Rooms = 8
+	}
+	if o.Items == 0 {
+		o.Items = o.Rooms / 2
+	}
+	o.Items = max(0, min(o.Items, len(genItems)))
+	if o.Locks == 0 {
+		o.Locks = o.Rooms / 3
+	}
+	o.Locks = max(0, min(o.Locks, o.Rooms-1))
+	return o
+}
+
+// genRoom — комната в клетке сетки
+type genRoom struct {
+	def    *RoomDef
+	at     [2]int
+	parent int // -1 у стартовой
+	depth  int
+}
+
+// generator хранит то, что уже построено
+type generator struct {
+	o     GenOptions
+	rnd   *rand.Rand
+	def   *WorldDef
+	rooms []*genRoom
+	taken map[[2]int]int // клетка -> номер комнаты
+	names []string
+}
+
+// generateWorldDef строит описание мира по параметрам o
+func generateWorldDef(o GenOptions) *WorldDef {
+	o = o.withDefaults()
+	g := &generator{
+		o:     o,
+		rnd:   rand.New(rand.NewPCG(o.Seed, 0)),
+		def:   &WorldDef{},
+		rooms: make([]*genRoom, 0, o.Rooms),
+		taken: make(map[[2]int]int),
+		names: slices.Clone(genRoomNames),
+	}
+	g.rnd.Shuffle(len(g.names), func(i, j int) { g.names[i], g.names[j] = g.names[j], g.names[i] })
+
+	g.growRooms()
+	g.addShortcuts()
+	g.def.Start = g.rooms[0].def.Name
+	g.addLocks()
+	g.addItems()
+	g.addQuest()
+	for _, r := range g.rooms {
+		g.def.Rooms = append(g.def.Rooms, *r.def)
+	}
+	return g.def
+}
+
+func (g *generator) addRoom(at [2]int, parent int) *genRoom {
+	name := g.names[len(g.rooms)%len(g.names)]
+	if n := len(g.rooms) / len(g.names); n > 0 {
+		name = fmt.Sprintf("%s-%d", name, n+1)
+	}
+	details := g.rnd.Perm(len(genDetails))
+	r := &genRoom{
+		def:    &RoomDef{Name: name, Description: genDetails[details[0]] + ", " + genDetails[details[1]]},
+		at:     at,
+		parent: parent,
+	}
+	if parent >= 0 {
+		r.depth = g.rooms[parent].depth + 1
+	}
+	r.def.At = &r.at
+	g.taken[at] = len(g.rooms)
+	g.rooms = append(g.rooms, r)
+	return r
+}
+
+// link соединяет комнаты проходами в обе стороны, dir — где to относительно from
+func link(from, to *genRoom, dir string) {
+	from.def.Paths = append(from.def.Paths, PathDef{To: to.def.Name, Dir: dir})
+	to.def.Paths = append(to.def.Paths, PathDef{To: from.def.Name, Dir: genOpposite[dir]})
+}
+
+// free — свободные клетки рядом с комнатой i, по сторонам из genSides
+func (g *generator) free(i int) []string {
+	var sides []string
+	for _, side := range genSides {
+		d := compassDelta[side]
+		if _, ok := g.taken[[2]int{g.rooms[i].at[0] + d[0], g.rooms[i].at[1] + d[1]}]; !ok {
+			sides = append(sides, side)
+		}
+	}
+	return sides
+}
+
+// growRooms ставит комнаты по клеткам: каждая новая — рядом с уже поставленной
+func (g *generator) growRooms() {
+	g.addRoom([2]int{0, 0}, -1)
+	for len(g.rooms) < g.o.Rooms {
+		var open []int
+		for i := range g.rooms {
+			if len(g.free(i)) > 0 {
+				open = append(open, i)
+			}
+		}
+		parent := open[g.rnd.IntN(len(open))]
+		sides := g.free(parent)
+		side := sides[g.rnd.IntN(len(sides))]
+		d := compassDelta[side]
+		p := g.rooms[parent]
+		link(p, g.addRoom([2]int{p.at[0] + d[0], p.at[1] + d[1]}, parent), side)
+	}
+}
+
+// addShortcuts добавляет лишние проходы между соседями, чтобы мир не был деревом
+func (g *generator) addShortcuts() {
+	for i, r := range g.rooms {
+		for _, side := range []string{"восток", "юг"} {
+			d := compassDelta[side]
+			j, ok := g.taken[[2]int{r.at[0] + d[0], r.at[1] + d[1]}]
+			if !ok || g.rooms[j].parent == i || r.parent == j || g.rnd.IntN(4) != 0 {
+				continue
+			}
+			link(r, g.rooms[j], side)
+		}
+	}
+}
+
+// behind сообщает, что комната i за дверью в комнату lock
+func (g *generator) behind(i, lock int) bool {
+	for ; i >= 0; i = g.rooms[i].parent {
+		if i == lock {
+			return true
+		}
+	}
+	return false
+}
+
+// addLocks запирает двери: проход от родителя к ребёнку, обратно всегда открыто.
+// Лишний проход в обход двери сделал бы её бесполезной, но не ломает мир.
+func (g *generator) addLocks() {
+	lockAt := make([]int, 0, g.o.Locks) // комнаты за дверями, в порядке выбора
+	for _, i := range g.rnd.Perm(len(g.rooms) - 1)[:g.o.Locks] {
+		lockAt = append(lockAt, i+1)
+	}
+	for n, child := range lockAt {
+		key := fmt.Sprintf("ключ-%d", n+1)
+		door := fmt.Sprintf("дверь-%d", n+1)
+		p := g.rooms[g.rooms[child].parent]
+		for k := range p.def.Paths {
+			if p.def.Paths[k].To == g.rooms[child].def.Name {
+				p.def.Paths[k].Lock = &LockDef{Name: door, Locked: true, Keys: []string{key}}
+			}
+		}
+		// ключ там, куда не нужно проходить через эту и следующие двери
+		var spots []int
+		for i := range g.rooms {
+			if !slices.ContainsFunc(lockAt[n:], func(later int) bool { return g.behind(i, later) }) {
+				spots = append(spots, i)
+			}
+		}
+		spot := g.rooms[spots[g.rnd.IntN(len(spots))]]
+		spot.def.Items = append(spot.def.Items, ItemDef{Name: key})
+		g.def.Items = append(g.def.Items, ItemDef{Name: key, Description: "ключ с биркой \"" + door + "\"", UsableOn: []string{door}})
+	}
+}
+
+// addItems кладёт рюкзак в стартовую комнату, а простые предметы — куда придётся
+func (g *generator) addItems() {
+	// в рюкзак влезает всё
+	g.def.Items = append(g.def.Items, ItemDef{Name: "рюкзак", Description: "вместительный рюкзак", Wearable: true, Capacity: g.o.Locks + g.o.Items})
+	g.rooms[0].def.Items = append(g.rooms[0].def.Items, ItemDef{Name: "рюкзак"})
+	for _, k := range g.rnd.Perm(len(genItems))[:g.o.Items] {
+		r := g.rooms[g.rnd.IntN(len(g.rooms))]
+		g.def.Items = append(g.def.Items, genItems[k])
+		r.def.Items = append(r.def.Items, ItemDef{Name: genItems[k].Name})
+	}
+}
+
+// addQuest — цель: самая дальняя от старта комната
+func (g *generator) addQuest() {
+	far := g.rooms[0]
+	for _, r := range g.rooms {
+		if r.depth > far.depth {
+			far = r
+		}
+	}
+	g.def.Quests = []QuestDef{{
+		Name:  "исследовать",
+		Done:  "всё исследовано",
+		Steps: []QuestStepDef{{Text: "дойти до " + far.def.Name, Reach: far.def.Name}},
+	}}
+}
+
+// generateWorld строит мир по параметрам o
+func generateWorld(o GenOptions) (*World, error) {
+	return newWorld(generateWorldDef(o))
+}
+
+// runGenerate пишет описание сгенерированного мира в out, его можно открыть через -world
+func runGenerate(o GenOptions, out io.Writer) error {
+	def := generateWorldDef(o)
+	if err := def.validate(); err != nil {
+		return fmt.Errorf("зерно %d: %w", o.Seed, err)
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(def)
+}
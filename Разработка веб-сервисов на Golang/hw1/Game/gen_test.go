@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerateIsReproducible(t *testing.T) {
+	gen := func(seed uint64) string {
+		buf := &bytes.Buffer{}
+		if err := runGenerate(GenOptions{Seed: seed, Rooms: 12}, buf); err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		return buf.String()
+	}
+	if gen(7) != gen(7) {
+		t.Errorf("same seed gave different worlds")
+	}
+	if gen(7) == gen(8) {
+		t.Errorf("different seeds gave the same world")
+	}
+}
+
+func TestGeneratedWorldsArePlayable(t *testing.T) {
+	for seed := uint64(0); seed < 100; seed++ {
+		rooms := 2 + int(seed%25)
+		w, err := generateWorld(GenOptions{Seed: seed, Rooms: rooms})
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if len(w.rooms) != rooms {
+			t.Errorf("seed %d: got %d rooms, want %d", seed, len(w.rooms), rooms)
+		}
+		// Lint проверяет, что каждый ключ добывается, не проходя через свою дверь
+		if err := w.Lint(); err != nil {
+			t.Errorf("seed %d: %v", seed, err)
+		}
+	}
+}
+
+func TestGeneratedWorldRunsCommands(t *testing.T) {
+	w, err := generateWorld(GenOptions{Seed: 42})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	world = w
+	defer initGame()
+
+	if answer := handleCommand("надеть рюкзак"); answer != "вы надели: рюкзак" {
+		t.Errorf("unexpected answer %q", answer)
+	}
+	if answer := handleCommand("задания"); !strings.HasPrefix(answer, "задания: исследовать (0/1): [ ] дойти до ") {
+		t.Errorf("unexpected answer %q", answer)
+	}
+	start := w.start
+	exit := start.exits[0]
+	answer := handleCommand("идти " + exit)
+	if path := start.paths[exit]; !path.locked() && !strings.Contains(answer, "можно пройти - ") {
+		t.Errorf("go %s: unexpected answer %q", exit, answer)
+	}
+}
+
+func TestGenerateClampsNegativeCounts(t *testing.T) {
+	w, err := generateWorld(GenOptions{Seed: 1, Rooms: 5, Items: -3, Locks: -2})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	// без дверей и простых предметов в мире только рюкзак
+	if len(w.items) != 1 || w.items["рюкзак"] == nil {
+		t.Errorf("got items %v, want only рюкзак", sortedKeys(w.items))
+	}
+}
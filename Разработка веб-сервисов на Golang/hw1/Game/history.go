@@ -0,0 +1,190 @@
+package main
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// --- История: "отменить", "повторить", "история" ---
+//
+// Каждая команда, после которой состояние мира стало другим, записывается
+// как изменение: снимок до команды и снимок после. Отмена возвращает мир
+// к снимку "до", повтор — к снимку "после". Снимки те же, что у "сохранить",
+// только в памяти.
+//
+// Мир у игроков общий, поэтому отменить можно только своё последнее
+// изменение и только если после него мир не менялся: иначе отмена затёрла
+// бы чужие ходы. Для этого у мира есть версия, она растёт при любом изменении,
+// а изменение помнит версию, при которой мир был в его снимке "после".
+// Мир меняется и между командами (расписание, триггеры в командах без
+// снимка) — тогда версию поднимает fire.
+//
+// Снимок — копия всего мира, и делается он дважды на каждую команду.
+// В сгенерированном мире на сотни комнат это уже заметно, поэтому команды,
+// которые только смотрят (Command.ReadOnly), обходятся без снимков.
+//
+// Отдельно у каждого игрока ведётся журнал его команд с ответами.
+// Журнал выгружается командой "история" в формате транскрипта и прогоняется
+// через -replay. Служебные (Command.Meta) и локальные (Command.Local) команды
+// попадают в него, только если изменили мир, как удачная отмена: иначе прогон
+// журнала заново писал бы сохранения и истории. Журнал уходит вместе с игроком (Session.Leave), поэтому сервер
+// с приходящими и уходящими игроками не копит чужие журналы, а у долгой игры
+// в нём остаются последние maxJournal команд.
+
+// maxHistory — сколько последних изменений можно отменить
+const maxHistory = 100
+
+// maxJournal — сколько последних команд игрока помнит журнал
+const maxJournal = 1000
+
+// change — изменение мира одной командой игрока
+type change struct {
+	player  string
+	command string
+	before  *snapshot
+	after   *snapshot
+	version int // версия мира, при которой изменение можно отменить (или повторить)
+}
+
+// journalEntry — команда игрока и ответ на неё
+type journalEntry struct {
+	command string
+	answer  string
+}
+
+// record записывает изменение, если команда что-то изменила. Вызывается под w.mu.
+func (w *World) record(p *Player, command string, before *snapshot) {
+	after := w.snapshot()
+	// часы идут и без команд, изменением они не считаются
+	cmp := *after
+	cmp.Clock = before.Clock
+	if reflect.DeepEqual(before, &cmp) {
+		return
+	}
+	w.version++
+	w.undone = nil
+	w.history = append(w.history, &change{
+		player:  p.id,
+		command: command,
+		before:  before,
+		after:   after,
+		version: w.version,
+	})
+	if len(w.history) > maxHistory {
+		w.history = w.history[len(w.history)-maxHistory:]
+	}
+}
+
+// lastChange — последнее изменение игрока в списке и его индекс
+func lastChange(list []*change, id string) (*change, int) {
+	for i := len(list) - 1; i >= 0; i-- {
+		if list[i].player == id {
+			return list[i], i
+		}
+	}
+	return nil, -1
+}
+
+// apply возвращает мир к снимку, не трогая игровое время
+func (w *World) apply(snap *snapshot) {
+	at := *snap
+	at.Clock = w.clock.Now()
+	w.restore(&at, true)
+}
+
+func (w *World) handleUndo(p *Player, _ []string) string {
+	c, i := lastChange(w.history, p.id)
+	switch {
+	case c == nil:
+		return p.tr("нечего отменять")
+	case i != len(w.history)-1 || c.version != w.version:
+		return p.tr("нельзя отменить: после вас мир уже изменился")
+	}
+	w.apply(c.before)
+	w.history = w.history[:i]
+	c.version = w.version
+	w.undone = append(w.undone, c)
+	// мир снова такой, каким его оставило предыдущее изменение
+	if i > 0 {
+		w.history[i-1].version = w.version
+	}
+	return p.tr("отменено: %s", c.command)
+}
+
+func (w *World) handleRedo(p *Player, _ []string) string {
+	c, i := lastChange(w.undone, p.id)
+	switch {
+	case c == nil:
+		return p.tr("нечего повторять")
+	case i != len(w.undone)-1 || c.version != w.version:
+		return p.tr("нельзя повторить: мир уже изменился")
+	}
+	w.apply(c.after)
+	w.undone = w.undone[:i]
+	c.version = w.version
+	w.history = append(w.history, c)
+	if i > 0 {
+		w.undone[i-1].version = w.version
+	}
+	return p.tr("повторено: %s", c.command)
+}
+
+// ExportHistory пишет все команды игрока id с ответами в формате транскрипта.
+// Транскрипт повторяет игру на новом мире, если игрок играл один,
+// а часы мира идут по командам.
+func (w *World) ExportHistory(out io.Writer, id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	p, ok := w.players[id]
+	if !ok {
+		return nil
+	}
+	return exportHistory(out, p)
+}
+
+// journaled сообщает, попадёт ли команда в журнал игрока, даже если не изменит
+// мир. Вызывается под w.mu до выполнения команды: после неё игрок может
+// оказаться в другой комнате.
+func (w *World) journaled(p *Player, command string) bool {
+	parts := strings.Fields(strings.ToLower(command))
+	if len(parts) == 0 {
+		return true
+	}
+	c, ok := w.lookup(p, p.lang().command(parts[0]))
+	return !ok || !c.Meta && !c.Local
+}
+
+func exportHistory(out io.Writer, p *Player) error {
+	for _, e := range p.journal {
+		if err := writeStep(out, e.command, e.answer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *World) handleHistory(p *Player, args []string) string {
+	path, ok := w.savePath(args, ".txt")
+	if !ok {
+		return p.tr("недопустимое имя сохранения - %s", path)
+	}
+	if w.saveDir != "" {
+		if err := os.MkdirAll(w.saveDir, 0o755); err != nil {
+			return p.tr("не удалось записать историю: %s", err)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return p.tr("не удалось записать историю: %s", err)
+	}
+	err = exportHistory(f, p)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return p.tr("не удалось записать историю: %s", err)
+	}
+	return p.tr("история записана")
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestUndoRedo(t *testing.T) {
+	initGame()
+	cases := []gameCase{
+		{1, "отменить", "нечего отменять"},
+		{2, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{3, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{4, "надеть рюкзак", "вы надели: рюкзак"},
+		{5, "взять ключи", "предмет добавлен в инвентарь: ключи"},
+		{6, "осмотреться", "на столе: конспекты. можно пройти - коридор"}, // не изменение
+		{7, "отменить", "отменено: взять ключи"},
+		{8, "осмотреться", "на столе: ключи, конспекты. можно пройти - коридор"},
+		{9, "отмена", "отменено: надеть рюкзак"},
+		{10, "осмотреться", "на столе: ключи, конспекты, на стуле: рюкзак. можно пройти - коридор"},
+		{11, "повторить", "повторено: надеть рюкзак"},
+		{12, "повторить", "повторено: взять ключи"},
+		{13, "повторить", "нечего повторять"},
+		{14, "инвентарь", "надето: рюкзак. в инвентаре 1 предмет: ключи"},
+		{15, "отменить", "отменено: взять ключи"},
+		{16, "взять конспекты", "предмет добавлен в инвентарь: конспекты"}, // новая ветка
+		{17, "повторить", "нечего повторять"},
+		{18, "отменить", "отменено: взять конспекты"},
+		{19, "отменить", "отменено: надеть рюкзак"},
+		{20, "отменить", "отменено: идти комната"},
+		{21, "осмотреться", "ничего интересного. можно пройти - кухня, комната, улица"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
+
+func TestUndoKeepsOtherPlayersMoves(t *testing.T) {
+	initGame()
+	anna, err := world.Join("анна")
+	if err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	boris, err := world.Join("борис")
+	if err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	anna.Handle("идти коридор")
+	anna.Handle("идти комната")
+	boris.Handle("идти коридор")
+
+	if got, want := anna.Handle("отменить"), "нельзя отменить: после вас мир уже изменился"; got != want {
+		t.Errorf("anna undo: got %q, want %q", got, want)
+	}
+	if got, want := boris.Handle("отменить"), "отменено: идти коридор"; got != want {
+		t.Errorf("boris undo: got %q, want %q", got, want)
+	}
+	// ход бориса отменён, теперь мир снова такой, каким его оставила анна
+	if got, want := anna.Handle("отменить"), "отменено: идти комната"; got != want {
+		t.Errorf("anna undo: got %q, want %q", got, want)
+	}
+	if got, want := boris.Handle("повторить"), "нельзя повторить: мир уже изменился"; got != want {
+		t.Errorf("boris redo: got %q, want %q", got, want)
+	}
+	if got, want := anna.Handle("осмотреться"), "ничего интересного. можно пройти - кухня, комната, улица"; got != want {
+		t.Errorf("anna look: got %q, want %q", got, want)
+	}
+}
+
+func TestExportHistoryReplays(t *testing.T) {
+	initGame()
+	for _, cmd := range []string{"идти коридор", "идти комната", "взять ключи", "отменить", "надеть рюкзак", "повторить", "инвентарь"} {
+		handleCommand(cmd)
+	}
+	tr := &bytes.Buffer{}
+	if err := world.ExportHistory(tr, defaultPlayerID); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if !strings.HasPrefix(tr.String(), "> идти коридор\n") {
+		t.Errorf("unexpected transcript:\n%s", tr)
+	}
+	out := &bytes.Buffer{}
+	failed, err := replayTranscript(tr, func() (*World, error) {
+		initGame()
+		return world, nil
+	}, out)
+	if err != nil || failed != 0 {
+		t.Errorf("replay: failed %d, err %v:\n%s", failed, err, out)
+	}
+}
+
+func TestJournalSkipsServiceCommands(t *testing.T) {
+	initGame()
+	world.saveDir = t.TempDir()
+	for _, cmd := range []string{"отменить", "идти коридор", "сохранить", "история", "отменить"} {
+		handleCommand(cmd)
+	}
+	tr := &bytes.Buffer{}
+	if err := world.ExportHistory(tr, defaultPlayerID); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	want := "> идти коридор\nничего интересного. можно пройти - кухня, комната, улица\n" +
+		"> отменить\nотменено: идти коридор\n"
+	if tr.String() != want {
+		t.Errorf("got transcript:\n%s\nwant:\n%s", tr, want)
+	}
+}
+
+func TestJournalKeepsLastCommands(t *testing.T) {
+	initGame()
+	for i := 0; i < maxJournal+10; i++ {
+		handleCommand("инвентарь")
+	}
+	p := world.players[defaultPlayerID]
+	if len(p.journal) != maxJournal {
+		t.Errorf("journal holds %d commands, want %d", len(p.journal), maxJournal)
+	}
+}
+
+func TestUndoKeepsScheduledChanges(t *testing.T) {
+	src := `{
+		"start": "а",
+		"items": [{"name": "рюкзак", "wearable": true}],
+		"schedule": [{"at": 2, "unlock": "а/б"}],
+		"rooms": [
+			{"name": "а", "description": "комната а", "items": ["рюкзак"], "paths": [{"to": "б", "locked": true}]},
+			{"name": "б", "description": "комната б", "paths": [{"to": "а"}]}
+		]
+	}`
+	if err := initGameFrom(strings.NewReader(src)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	cases := []gameCase{
+		{1, "надеть рюкзак", "вы надели: рюкзак"},
+		{2, "осмотреться", "комната а. можно пройти - б"}, // на втором ходу дверь отперта расписанием
+		{3, "отменить", "нельзя отменить: после вас мир уже изменился"},
+		{4, "идти б", "комната б. можно пройти - а"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
+
+func TestUndoKeepsTriggersOfReadOnlyCommands(t *testing.T) {
+	src := `{
+		"start": "а",
+		"items": [{"name": "рюкзак", "wearable": true}],
+		"rooms": [
+			{"name": "а", "description": "комната а", "items": ["рюкзак"], "paths": [{"to": "б", "locked": true}],
+				"triggers": [{"on": "look", "unlock": "а/б", "say": "в стене щёлкнуло"}]},
+			{"name": "б", "description": "комната б", "paths": [{"to": "а"}]}
+		]
+	}`
+	if err := initGameFrom(strings.NewReader(src)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	cases := []gameCase{
+		{1, "надеть рюкзак", "вы надели: рюкзак"},
+		{2, "инвентарь", "надето: рюкзак. инвентарь пуст"},
+		{3, "осмотреться", "комната а. можно пройти - б. в стене щёлкнуло"}, // снимка нет, но дверь открыл триггер
+		{4, "отменить", "нельзя отменить: после вас мир уже изменился"},
+		{5, "идти б", "комната б. можно пройти - а"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
+
+func TestJournalLeavesWithPlayer(t *testing.T) {
+	initGame()
+	s, err := world.Join("анна")
+	if err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	s.Handle("идти коридор")
+	s.Leave()
+	if _, err := world.Join("анна"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	tr := &bytes.Buffer{}
+	if err := world.ExportHistory(tr, "анна"); err != nil || tr.Len() != 0 {
+		t.Errorf("journal survived leave: %q, err %v", tr, err)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+)
+
+// --- Инвентарь и предметы игрока ---
+
+func (w *World) handleInventory(p *Player, _ []string) string {
+	if len(p.worn) == 0 && len(p.inventory) == 0 {
+		return p.tr("у вас ничего нет")
+	}
+	parts := []string{}
+	if len(p.worn) > 0 {
+		parts = append(parts, p.tr("надето: %s", strings.Join(sortedKeys(p.worn), ", ")))
+	}
+	if n := len(p.inventory); n > 0 {
+		parts = append(parts, p.tr("в инвентаре %s: %s", p.lang().plural(n, "предмет"), strings.Join(sortedKeys(p.inventory), ", ")))
+	} else {
+		parts = append(parts, p.tr("инвентарь пуст"))
+	}
+	return strings.Join(parts, ". ")
+}
+
+// handleExamine показывает описание предмета у игрока или в текущей комнате,
+// мебели или персонажа
+func (w *World) handleExamine(p *Player, args []string) string {
+	name := args[0]
+	it := p.reachable(name)
+	if c := p.room.container(name); it == nil && c != nil {
+		return w.examineContainer(p, c)
+	}
+	if npc, ok := p.room.npcs[name]; it == nil && ok {
+		if npc.description == "" {
+			return p.tr("%s - ничего особенного", name)
+		}
+		return npc.description
+	}
+	if it == nil {
+		return p.tr("нет такого")
+	}
+	if it.description == "" {
+		return p.tr("%s - ничего особенного", name)
+	}
+	return it.description
+}
+
+// handleDrop выкладывает предмет в текущую комнату: на пол или, если сказано,
+// в мебель ("положить ключи в шкаф"). Надетый контейнер можно снять,
+// только если остальные вещи поместятся без него.
+func (w *World) handleDrop(p *Player, args []string) string {
+	name := args[0]
+	var into *Container
+	if len(args) > 1 {
+		if into = p.room.container(args[1]); into == nil {
+			return p.tr("здесь нет - %s", args[1])
+		}
+		if into.closed {
+			return p.tr("закрыто - %s", into.name)
+		}
+	}
+	it, carried := p.inventory[name]
+	if !carried {
+		it = p.worn[name]
+	}
+	if it == nil {
+		return p.tr("нет предмета в инвентаре - %s", name)
+	}
+	if !carried && len(p.inventory) > p.capacity()-it.capacity {
+		return p.tr("сначала выложите вещи - %s", name)
+	}
+
+	msgs, denied := w.emit(&Event{Kind: EventDrop, Player: p, Room: p.room, Item: it})
+	if denied {
+		return deniedAnswer(p, msgs)
+	}
+	delete(p.inventory, name)
+	delete(p.worn, name)
+	if into != nil {
+		into.items[name] = it
+	} else {
+		p.room.items[name] = it
+	}
+	return withEvents(p.tr("вы выложили: %s", name), msgs)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestInventoryExamineDrop(t *testing.T) {
+	initGame()
+	cases := []gameCase{
+		{1, "инвентарь", "у вас ничего нет"},
+		{2, "осмотреть чай", "остывший чай в кружке"},
+		{3, "осмотреть ключи", "нет такого"}, // ключи в другой комнате
+		{4, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{5, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{6, "надеть рюкзак", "вы надели: рюкзак"},
+		{7, "инвентарь", "надето: рюкзак. инвентарь пуст"},
+		{8, "взять конспекты", "предмет добавлен в инвентарь: конспекты"},
+		{9, "взять ключи", "предмет добавлен в инвентарь: ключи"},
+		{10, "инвентарь", "надето: рюкзак. в инвентаре 2 предмета: ключи, конспекты"},
+		{11, "осмотреть рюкзак", "старый школьный рюкзак"},
+		{12, "выложить рюкзак", "сначала выложите вещи - рюкзак"},
+		{13, "выложить конспекты", "вы выложили: конспекты"},
+		{14, "осмотреться", "на полу: конспекты. можно пройти - коридор"}, // выложенное лежит на полу, а не на столе
+		{15, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{16, "выложить ключи", "вы выложили: ключи"},
+		{17, "осмотреться", "ничего интересного, на полу: ключи. можно пройти - кухня, комната, улица"},
+		{18, "выложить ключи", "нет предмета в инвентаре - ключи"},
+		{19, "выложить рюкзак", "вы выложили: рюкзак"},
+		{20, "инвентарь", "у вас ничего нет"},
+		{21, "осмотреть ключи", "ключи от входной двери"},
+		{22, "выложить", "что выложить?"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// --- Предметы ---
+
+var errDuplicateItem = errors.New("предмет объявлен повторно")
+
+// Item — предмет мира. Каждый предмет существует в одном экземпляре:
+// лежит в комнате, в инвентаре или надет на игрока.
+type Item struct {
+	name        string
+	description string
+	wearable    bool     // можно надеть
+	capacity    int      // сколько предметов в него помещается, если надет
+	usableOn    []string // к чему можно применить, пусто — без ограничений
+	lock        *Lock    // запертый контейнер нельзя надеть
+	triggers    []*Trigger
+}
+
+// canUseOn сообщает, можно ли применить предмет к target
+func (it *Item) canUseOn(target string) bool {
+	if len(it.usableOn) == 0 {
+		return true
+	}
+	for _, t := range it.usableOn {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+// ItemDef описывает предмет в файле мира. В списке предметов комнаты
+// вместо объекта можно указать просто имя: тогда свойства берутся из
+// общего каталога WorldDef.Items, а если их там нет — предмет простой.
+type ItemDef struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Wearable    bool         `json:"wearable,omitempty"`
+	Capacity    int          `json:"capacity,omitempty"`
+	UsableOn    []string     `json:"usableOn,omitempty"`
+	Lock        *LockDef     `json:"lock,omitempty"`
+	Triggers    []TriggerDef `json:"triggers,omitempty"`
+}
+
+// UnmarshalJSON принимает как "имя", так и полный объект предмета
+func (d *ItemDef) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		*d = ItemDef{Name: name}
+		return nil
+	}
+	type plain ItemDef
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*plain)(d))
+}
+
+// isRef — предмет задан только именем
+func (d ItemDef) isRef() bool {
+	return d.Description == "" && !d.Wearable && d.Capacity == 0 && len(d.UsableOn) == 0 && d.Lock == nil && len(d.Triggers) == 0
+}
+
+func (d ItemDef) item() *Item {
+	return &Item{
+		name:        d.Name,
+		description: d.Description,
+		wearable:    d.Wearable,
+		capacity:    d.Capacity,
+		usableOn:    d.UsableOn,
+		triggers:    buildTriggers(d.Triggers),
+	}
+}
+
+// validateItems проверяет каталог и размещение предметов по комнатам
+func (d *WorldDef) validateItems() []error {
+	var errs []error
+	catalog := make(map[string]bool, len(d.Items))
+	for _, it := range d.Items {
+		switch {
+		case it.Name == "":
+			errs = append(errs, fmt.Errorf("каталог предметов: %w", errEmptyName))
+		case catalog[it.Name]:
+			errs = append(errs, fmt.Errorf("предмет %q: %w", it.Name, errDuplicateItem))
+		}
+		catalog[it.Name] = true
+		errs = append(errs, validateTriggers(fmt.Sprintf("предмет %q", it.Name), it.Triggers, d)...)
+	}
+
+	placed := make(map[string]string)
+	for _, rd := range d.Rooms {
+		for _, it := range rd.allItems() {
+			if it.Name == "" {
+				errs = append(errs, fmt.Errorf("комната %q, предмет: %w", rd.Name, errEmptyName))
+				continue
+			}
+			if other, ok := placed[it.Name]; ok {
+				errs = append(errs, fmt.Errorf("комната %q, предмет %q: %w (уже лежит в %q)", rd.Name, it.Name, errDuplicateItem, other))
+				continue
+			}
+			placed[it.Name] = rd.Name
+			if catalog[it.Name] && !it.isRef() {
+				errs = append(errs, fmt.Errorf("комната %q, предмет %q: %w (есть в каталоге)", rd.Name, it.Name, errDuplicateItem))
+			}
+			errs = append(errs, validateTriggers(fmt.Sprintf("предмет %q", it.Name), it.Triggers, d)...)
+		}
+	}
+	return errs
+}
+
+// buildItems создаёт все предметы мира: из каталога и объявленные прямо в комнатах
+func (w *World) buildItems(d *WorldDef) {
+	w.items = make(map[string]*Item, len(d.Items))
+	for _, it := range d.Items {
+		w.items[it.Name] = it.item()
+		w.items[it.Name].lock = w.buildLock(it.Lock)
+	}
+	for _, rd := range d.Rooms {
+		for _, it := range rd.allItems() {
+			if _, ok := w.items[it.Name]; !ok {
+				w.items[it.Name] = it.item()
+				w.items[it.Name].lock = w.buildLock(it.Lock)
+			}
+		}
+	}
+}
+
+// --- Предметы игрока ---
+
+// capacity — сколько предметов игрок может унести в надетых контейнерах
+func (p *Player) capacity() int {
+	total := 0
+	for _, it := range p.worn {
+		total += it.capacity
+	}
+	return total
+}
+
+// wears сообщает, надет ли на игрока предмет name
+func (p *Player) wears(name string) bool {
+	_, ok := p.worn[name]
+	return ok
+}
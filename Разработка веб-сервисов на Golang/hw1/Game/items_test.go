@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const itemsTestWorld = `{
+	"start": "прихожая",
+	"items": [
+		{"name": "сумка", "wearable": true, "capacity": 1},
+		{"name": "шляпа", "wearable": true}
+	],
+	"rooms": [
+		{
+			"name": "прихожая",
+			"description": "тесно",
+			"items": ["сумка", "шляпа", "зонт", {"name": "отмычка", "usableOn": ["решётка"]}],
+			"paths": [{"to": "двор", "locked": true, "unlockItem": "отмычка", "unlockMsg": "решётка поднята"}]
+		},
+		{"name": "двор", "description": "сыро", "paths": [{"to": "прихожая"}]}
+	]
+}`
+
+func TestItemProperties(t *testing.T) {
+	if err := initGameFrom(strings.NewReader(itemsTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	cases := []gameCase{
+		{1, "надеть шляпа", "вы надели: шляпа"},    // надевается любой wearable предмет
+		{2, "взять зонт", "некуда класть"},         // шляпа — не контейнер
+		{3, "надеть зонт", "нельзя надеть - зонт"}, // зонт не надевается
+		{4, "надеть сумка", "вы надели: сумка"},
+		{5, "взять отмычка", "предмет добавлен в инвентарь: отмычка"},
+		{6, "взять зонт", "больше некуда класть"}, // в сумку влезает один предмет
+		{7, "применить отмычка двор", "не к чему применить"},
+		{8, "применить отмычка решётка", "не к чему применить"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+	if it := world.items["отмычка"]; it == nil || !it.canUseOn("решётка") || it.canUseOn("двор") {
+		t.Errorf("inline item properties were not loaded: %+v", it)
+	}
+}
+
+func TestItemValidation(t *testing.T) {
+	cases := []string{
+		// один предмет в двух комнатах
+		`{"start": "а", "rooms": [{"name": "а", "items": ["ключ"], "paths": [{"to": "б"}]}, {"name": "б", "items": ["ключ"]}]}`,
+		// предмет из каталога переопределён в комнате
+		`{"start": "а", "items": [{"name": "ключ", "wearable": true}], "rooms": [{"name": "а", "items": [{"name": "ключ", "capacity": 2}]}]}`,
+		// повтор в каталоге
+		`{"start": "а", "items": ["ключ", "ключ"], "rooms": [{"name": "а"}]}`,
+	}
+	for i, src := range cases {
+		def, err := parseWorldDef(strings.NewReader(src))
+		if err != nil {
+			t.Fatalf("case %d: parse: %v", i, err)
+		}
+		if _, err := newWorld(def); !errors.Is(err, errDuplicateItem) {
+			t.Errorf("case %d: expected errDuplicateItem, got %v", i, err)
+		}
+	}
+}
+
+func TestLoadSnapshotV1(t *testing.T) {
+	initGame()
+	v1 := `{
+		"version": 1,
+		"rooms": {
+			"кухня": {"items": ["чай"]},
+			"коридор": {"items": [], "locked": ["улица"]},
+			"комната": {"items": ["конспекты"]},
+			"улица": {"items": []}
+		},
+		"players": {"игрок": {"room": "комната", "inventory": ["ключи"], "hasBackpack": true}}
+	}`
+	if err := world.Load(strings.NewReader(v1)); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if answer := handleCommand("взять конспекты"); answer != "предмет добавлен в инвентарь: конспекты" {
+		t.Errorf("backpack from v1 snapshot is not worn: %q", answer)
+	}
+}
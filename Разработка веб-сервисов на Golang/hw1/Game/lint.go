@@ -0,0 +1,345 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// --- Проверка проходимости мира ---
+//
+// validate проверяет, что описание мира собрано правильно, а Lint — что в него
+// можно играть: все комнаты достижимы, ключи можно добыть, предметы — взять,
+// а из любой комнаты можно вернуться к началу.
+//
+// Проверка оптимистичная: условия триггеров и ответов персонажей считаются
+// выполнимыми, коды замков — известными. Поэтому Lint сообщает только о том,
+// что не получится ни при каком ходе игры. Хуки комнат и подписчики из кода
+// (World.On) не учитываются.
+
+// Комнаты, недостижимые за замками, Lint сообщает как errUnreachableRoom:
+// validate находит только те, к которым нет пути вовсе.
+var (
+	errUnobtainableKey = errors.New("ключ недоступен")
+	errKeyBehindLock   = errors.New("ключ за своим же замком")
+	errUntakeableItem  = errors.New("предмет не взять")
+	errOneWayTrap      = errors.New("ловушка")
+)
+
+// reach — что игрок может получить в мире при самом удачном ходе игры
+type reach struct {
+	rooms map[*Room]bool
+	items map[string]bool
+	open  map[*Lock]bool // запертые замки, которые можно открыть
+
+	changed bool // за текущий проход что-то добавилось
+}
+
+// explore находит достижимые комнаты, предметы и открываемые замки.
+// Замок assume считается открытым, чем бы его ни открывали.
+func (w *World) explore(assume *Lock) *reach {
+	rc := &reach{
+		rooms: map[*Room]bool{w.start: true},
+		items: make(map[string]bool),
+		open:  make(map[*Lock]bool),
+	}
+	if assume != nil {
+		rc.open[assume] = true
+	}
+	for _, p := range w.players {
+		rc.rooms[p.room] = true
+		for name := range p.inventory {
+			rc.items[name] = true
+		}
+		for name := range p.worn {
+			rc.items[name] = true
+		}
+	}
+
+	for rc.changed = true; rc.changed; {
+		rc.changed = false
+		for r := range rc.rooms {
+			rc.visitRoom(w, r)
+			rc.visitNPCs(w, r)
+			rc.visitPaths(w, r)
+		}
+		rc.visitItems(w)
+		rc.visitSchedule(w)
+		rc.visitRecipes(w)
+	}
+	return rc
+}
+
+func (rc *reach) addRoom(r *Room) {
+	if r != nil && !rc.rooms[r] {
+		rc.rooms[r], rc.changed = true, true
+	}
+}
+
+func (rc *reach) addItem(name string) {
+	if name != "" && !rc.items[name] {
+		rc.items[name], rc.changed = true, true
+	}
+}
+
+func (rc *reach) openLock(l *Lock) {
+	if l != nil && !rc.open[l] {
+		rc.open[l], rc.changed = true, true
+	}
+}
+
+// fire — триггер может положить предмет в свою комнату и отпереть любой выход
+func (rc *reach) fire(w *World, t *Trigger, room *Room) {
+	if t.spawn != "" && (room == nil || rc.rooms[room]) {
+		rc.addItem(t.spawn)
+	}
+	if t.unlock != "" {
+		rc.openLock(w.pathByRef(t.unlock).lock)
+	}
+}
+
+// visitRoom — предметы комнаты и её мебели и триггеры комнаты
+func (rc *reach) visitRoom(w *World, r *Room) {
+	for name := range r.items {
+		rc.addItem(name)
+	}
+	// закрытую мебель можно открыть, запертую — как выход
+	for _, c := range r.containers {
+		if l := c.lock; l != nil && l.locked && rc.canOpen(l) {
+			rc.openLock(l)
+		}
+		if c.lock == nil || !c.lock.locked || rc.open[c.lock] {
+			for name := range c.items {
+				rc.addItem(name)
+			}
+		}
+	}
+	for _, t := range r.triggers {
+		rc.fire(w, t, r)
+	}
+}
+
+// visitNPCs — всё, что персонажи комнаты дают в разговоре
+func (rc *reach) visitNPCs(w *World, r *Room) {
+	for _, npc := range r.npcs {
+		for _, node := range npc.nodes {
+			for _, c := range node.choices {
+				rc.addItem(c.give)
+				rc.fire(w, c.effect, r)
+			}
+		}
+	}
+}
+
+// visitPaths — выходы комнаты, которые можно пройти или отпереть
+func (rc *reach) visitPaths(w *World, r *Room) {
+	for _, name := range r.exits {
+		path := r.paths[name]
+		for _, t := range path.triggers {
+			rc.fire(w, t, r)
+		}
+		if l := path.lock; l != nil && l.locked && !path.oneWay && rc.canOpen(l) {
+			rc.openLock(l)
+		}
+		if !path.locked() || rc.open[path.lock] {
+			rc.addRoom(path.to)
+		}
+	}
+}
+
+// visitItems — триггеры и замки добытых предметов
+func (rc *reach) visitItems(w *World) {
+	for name := range rc.items {
+		it := w.items[name]
+		for _, t := range it.triggers {
+			rc.fire(w, t, nil)
+		}
+		if l := it.lock; l != nil && l.locked && rc.canOpen(l) {
+			rc.openLock(l)
+		}
+	}
+}
+
+// visitSchedule — события расписания рано или поздно случатся
+func (rc *reach) visitSchedule(w *World) {
+	for _, s := range w.schedule {
+		rc.fire(w, s.action, s.room)
+	}
+}
+
+// visitRecipes — предметы, которые можно собрать из добытых
+func (rc *reach) visitRecipes(w *World) {
+	for _, rp := range w.recipes {
+		if rc.items[rp.items[0]] && rc.items[rp.items[1]] && (rp.room == "" || rc.rooms[w.rooms[rp.room]]) {
+			rc.addItem(rp.result)
+		}
+	}
+}
+
+// canOpen — замок открывается кодом или добытым ключом
+func (rc *reach) canOpen(l *Lock) bool {
+	if l.code != "" {
+		return true
+	}
+	for _, key := range l.keys {
+		if rc.items[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// canCarry — у игрока может появиться контейнер, куда класть предметы
+func (rc *reach) canCarry(w *World) bool {
+	for name := range rc.items {
+		it := w.items[name]
+		if it.wearable && it.capacity > 0 && (it.lock == nil || !it.lock.locked || rc.open[it.lock]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint проверяет, что в мир можно играть, и возвращает все найденные проблемы
+func (w *World) Lint() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	rc := w.explore(nil)
+	var errs []error
+
+	for _, name := range sortedKeys(w.rooms) {
+		if !rc.rooms[w.rooms[name]] {
+			errs = append(errs, fmt.Errorf("комната %q: %w %q", name, errUnreachableRoom, w.start.name))
+		}
+	}
+
+	checked := make(map[*Lock]bool)
+	for _, name := range sortedKeys(w.rooms) {
+		r := w.rooms[name]
+		if !rc.rooms[r] {
+			continue
+		}
+		for _, exit := range r.exits {
+			l := r.paths[exit].lock
+			if l == nil || !l.locked || len(l.keys) == 0 || rc.open[l] || checked[l] {
+				continue
+			}
+			checked[l] = true
+			where := fmt.Sprintf("комната %q, выход %q, замок %q", name, exit, l.name)
+			if w.explore(l).canOpen(l) {
+				errs = append(errs, fmt.Errorf("%s: %w: %s", where, errKeyBehindLock, strings.Join(l.keys, ", ")))
+			} else {
+				errs = append(errs, fmt.Errorf("%s: %w: %s", where, errUnobtainableKey, strings.Join(l.keys, ", ")))
+			}
+		}
+	}
+
+	if !rc.canCarry(w) {
+		for _, name := range sortedKeys(rc.items) {
+			if !w.items[name].wearable {
+				errs = append(errs, fmt.Errorf("%w: %s: некуда положить", errUntakeableItem, name))
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(w.rooms) {
+		r := w.rooms[name]
+		if rc.rooms[r] && r != w.start && !rc.leadsTo(r, w.start) {
+			errs = append(errs, fmt.Errorf("%w: из комнаты %q не вернуться в %q", errOneWayTrap, name, w.start.name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// leadsTo сообщает, можно ли дойти из from в to по проходимым выходам
+func (rc *reach) leadsTo(from, to *Room) bool {
+	seen := map[*Room]bool{from: true}
+	queue := []*Room{from}
+	for len(queue) > 0 {
+		r := queue[0]
+		queue = queue[1:]
+		if r == to {
+			return true
+		}
+		for _, path := range r.paths {
+			if (!path.locked() || rc.open[path.lock]) && !seen[path.to] {
+				seen[path.to] = true
+				queue = append(queue, path.to)
+			}
+		}
+	}
+	return false
+}
+
+// WriteDOT выводит граф комнат в формате Graphviz: запертые выходы
+// пунктиром с именем замка, недостижимые комнаты серым, старт — двойным кругом
+func (w *World) WriteDOT(out io.Writer) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	rc := w.explore(nil)
+	b := &strings.Builder{}
+	b.WriteString("digraph world {\n")
+	for _, name := range sortedKeys(w.rooms) {
+		r := w.rooms[name]
+		switch {
+		case r == w.start:
+			fmt.Fprintf(b, "\t%q [shape=doublecircle];\n", name)
+		case !rc.rooms[r]:
+			fmt.Fprintf(b, "\t%q [style=filled, fillcolor=gray];\n", name)
+		}
+	}
+	for _, name := range sortedKeys(w.rooms) {
+		r := w.rooms[name]
+		for _, exit := range r.exits {
+			path := r.paths[exit]
+			attrs := []string{}
+			label := []string{}
+			if exit != path.to.name {
+				label = append(label, exit)
+			}
+			if path.locked() {
+				label = append(label, path.lock.name)
+				attrs = append(attrs, "style=dashed")
+			}
+			if len(label) > 0 {
+				attrs = append([]string{fmt.Sprintf("label=%q", strings.Join(label, ": "))}, attrs...)
+			}
+			fmt.Fprintf(b, "\t%q -> %q", name, path.to.name)
+			if len(attrs) > 0 {
+				fmt.Fprintf(b, " [%s]", strings.Join(attrs, ", "))
+			}
+			b.WriteString(";\n")
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// runLint проверяет мир и, если задан dotFile, записывает в него граф
+func runLint(worldFile, dotFile string, out io.Writer) error {
+	w, err := openWorld(worldFile)
+	if err != nil {
+		return err
+	}
+	if dotFile != "" {
+		f, err := os.Create(dotFile)
+		if err != nil {
+			return err
+		}
+		err = w.WriteDOT(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := w.Lint(); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "мир в порядке")
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const lintTestWorld = `{
+	"start": "холл",
+	"items": [{"name": "пропуск"}],
+	"rooms": [
+		{
+			"name": "холл",
+			"description": "в холле",
+			"items": ["монета"],
+			"paths": [
+				{"to": "склад", "lock": {"locked": true, "keys": ["ключ"]}},
+				{"to": "архив", "lock": {"name": "турникет", "locked": true, "keys": ["пропуск"]}},
+				{"to": "яма"}
+			]
+		},
+		{"name": "склад", "description": "на складе", "items": ["ключ"], "paths": [{"to": "холл"}]},
+		{"name": "архив", "description": "в архиве", "paths": [{"to": "холл"}]},
+		{"name": "яма", "description": "в яме", "paths": [{"name": "наверх", "to": "холл", "lock": {"name": "решётка", "locked": true, "keys": ["ключ"]}, "oneWay": true}]}
+	]
+}`
+
+func TestLintDefaultWorld(t *testing.T) {
+	initGame()
+	if err := world.Lint(); err != nil {
+		t.Errorf("default world: %v", err)
+	}
+}
+
+func TestLintFindsProblems(t *testing.T) {
+	w, err := readWorld(strings.NewReader(lintTestWorld))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	err = w.Lint()
+	for _, want := range []error{errUnreachableRoom, errKeyBehindLock, errUnobtainableKey, errUntakeableItem, errOneWayTrap} {
+		if !errors.Is(err, want) {
+			t.Errorf("expected %v in:\n%v", want, err)
+		}
+	}
+	for _, want := range []string{
+		`комната "склад": комната недостижима из стартовой "холл"`,
+		`комната "архив": комната недостижима из стартовой "холл"`,
+		`выход "склад", замок "дверь": ключ за своим же замком: ключ`,
+		`выход "архив", замок "турникет": ключ недоступен: пропуск`,
+		`предмет не взять: монета: некуда положить`,
+		`ловушка: из комнаты "яма" не вернуться в "холл"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in:\n%v", want, err)
+		}
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	initGame()
+	buf := &bytes.Buffer{}
+	if err := world.WriteDOT(buf); err != nil {
+		t.Fatalf("dot: %v", err)
+	}
+	want := `digraph world {
+	"кухня" [shape=doublecircle];
+	"комната" -> "коридор";
+	"коридор" -> "кухня";
+	"коридор" -> "комната";
+	"коридор" -> "улица" [label="дверь", style=dashed];
+	"кухня" -> "коридор";
+	"улица" -> "коридор" [label="домой"];
+}
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf, want)
+	}
+
+	w, err := readWorld(strings.NewReader(lintTestWorld))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	buf.Reset()
+	if err := w.WriteDOT(buf); err != nil {
+		t.Fatalf("dot: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\t\"склад\" [style=filled, fillcolor=gray];\n") {
+		t.Errorf("unreachable room is not marked:\n%s", buf)
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// --- Локализация ---
+//
+// Исходные тексты сообщений написаны по-русски и сами служат ключами каталога,
+// как в gettext: p.tr("нет пути в %s", dir). Если перевода нет, используется
+// исходный текст, поэтому русской локали каталог не нужен, а тексты из описания
+// мира (названия, описания комнат и предметов) выводятся как есть.
+
+// defaultLocale — язык новых игроков
+const defaultLocale = "ru"
+
+var errUnknownLocale = errors.New("неизвестный язык")
+
+// Locale — каталог сообщений и глаголов команд одного языка
+type Locale struct {
+	name    string
+	title   string              // как язык называет себя
+	msgs    map[string]string   // перевод по исходному тексту сообщения
+	verbs   map[string]string   // глагол этого языка -> имя команды
+	preps   map[string]bool     // предлоги, которые выбрасываются из аргументов
+	forms   map[string][]string // формы слов для чисел, см. plural
+	pluralN func(n int) int     // номер формы слова для числа n
+	names   map[string]string   // имя команды -> глагол для вывода, строится по verbs
+}
+
+func newLocale(l *Locale) *Locale {
+	l.names = make(map[string]string, len(l.verbs))
+	for verb, name := range l.verbs {
+		// в "помощь" попадает самый длинный глагол: "inventory", а не "inv"
+		if cur, ok := l.names[name]; !ok || len(verb) > len(cur) {
+			l.names[name] = verb
+		}
+	}
+	return l
+}
+
+// locales — все доступные языки по имени
+var locales = map[string]*Locale{
+	"ru": newLocale(&Locale{
+		name:  "ru",
+		title: "русский",
+		forms: map[string][]string{
+			"предмет": {"предмет", "предмета", "предметов"},
+		},
+		pluralN: pluralRu,
+	}),
+	"en": newLocale(&Locale{
+		name:    "en",
+		title:   "english",
+		msgs:    enMessages,
+		verbs:   enVerbs,
+		preps:   map[string]bool{"to": true, "in": true, "into": true, "on": true, "at": true, "with": true, "the": true, "a": true},
+		forms:   map[string][]string{"предмет": {"item", "items"}},
+		pluralN: pluralEn,
+	}),
+}
+
+// tr переводит сообщение на язык локали и подставляет аргументы как fmt.Sprintf
+func (l *Locale) tr(msg string, args ...any) string {
+	if t, ok := l.msgs[msg]; ok {
+		msg = t
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// plural согласует слово с числом: plural(2, "предмет") -> "2 предмета"
+func (l *Locale) plural(n int, word string) string {
+	forms, ok := l.forms[word]
+	if !ok {
+		return fmt.Sprintf("%d %s", n, word)
+	}
+	i := l.pluralN(n)
+	if i >= len(forms) {
+		i = len(forms) - 1
+	}
+	return fmt.Sprintf("%d %s", n, forms[i])
+}
+
+// verb — как команда name называется на этом языке
+func (l *Locale) verb(name string) string {
+	if v, ok := l.names[name]; ok {
+		return v
+	}
+	return name
+}
+
+// command переводит глагол этого языка в имя команды
+func (l *Locale) command(verb string) string {
+	if name, ok := l.verbs[verb]; ok {
+		return name
+	}
+	return verb
+}
+
+// spellings — как игрок может написать команду: имя, синонимы и глаголы языка
+func (l *Locale) spellings(c *Command) []string {
+	names := append([]string{c.Name}, c.Aliases...)
+	for verb, name := range l.verbs {
+		if name == c.Name {
+			names = append(names, verb)
+		}
+	}
+	return names
+}
+
+// pluralRu: 1 предмет, 2 предмета, 5 предметов, 21 предмет, 11 предметов
+func pluralRu(n int) int {
+	switch n10, n100 := n%10, n%100; {
+	case n10 == 1 && n100 != 11:
+		return 0
+	case n10 >= 2 && n10 <= 4 && (n100 < 12 || n100 > 14):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// pluralEn: 1 item, 2 items
+func pluralEn(n int) int {
+	if n == 1 {
+		return 0
+	}
+	return 1
+}
+
+// tr переводит сообщение на язык игрока
+func (p *Player) tr(msg string, args ...any) string {
+	return p.lang().tr(msg, args...)
+}
+
+func (p *Player) lang() *Locale {
+	if p.locale == nil {
+		return locales[defaultLocale]
+	}
+	return p.locale
+}
+
+// setLocale переключает язык игрока, вызывается под w.mu
+func (p *Player) setLocale(name string) error {
+	l, ok := locales[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", errUnknownLocale, name)
+	}
+	p.locale = l
+	return nil
+}
+
+// SetLocale переключает язык сообщений и команд игрока сессии
+func (s *Session) SetLocale(name string) error {
+	s.world.mu.Lock()
+	defer s.world.mu.Unlock()
+	return s.player.setLocale(name)
+}
+
+func (w *World) handleLocale(p *Player, args []string) string {
+	if len(args) == 0 {
+		names := sortedKeys(locales)
+		return p.tr("язык: %s, доступны: %s", p.lang().title, strings.Join(names, ", "))
+	}
+	if err := p.setLocale(args[0]); err != nil {
+		return p.tr("нет такого языка - %s", args[0])
+	}
+	return p.tr("язык: %s", p.lang().title)
+}
+
+// enVerbs — английские глаголы команд. Русские глаголы работают при любом языке.
+var enVerbs = map[string]string{
+	"look":      "осмотреться",
+	"go":        "идти",
+	"take":      "взять",
+	"wear":      "надеть",
+	"drop":      "выложить",
+	"inventory": "инвентарь",
+	"inv":       "инвентарь",
+	"examine":   "осмотреть",
+	"quests":    "задания",
+	"use":       "применить",
+	"save":      "сохранить",
+	"load":      "загрузить",
+	"help":      "помощь",
+	"language":  "язык",
+	"talk":      "поговорить",
+	"time":      "время",
+	"answer":    "ответить",
+	"enter":     "ввести",
+	"type":      "ввести",
+	"lock":      "закрыть",
+	"close":     "закрыть",
+	"open":      "открыть",
+	"undo":      "отменить",
+	"redo":      "повторить",
+	"history":   "история",
+	"say":       "сказать",
+	"map":       "карта",
+	"shout":     "крикнуть",
+}
+
+// enMessages — английский каталог сообщений движка
+var enMessages = map[string]string{
+	// комнаты
+	"ничего": "nothing",
+	"{description}. можно пройти - {exits}":             "{description}. exits - {exits}",
+	"{description}, {contents}. можно пройти - {exits}": "{description}, {contents}. exits - {exits}",
+	"{contents}. можно пройти - {exits}":                "{contents}. exits - {exits}",
+	"пустая комната. можно пройти - {exits}":            "empty room. exits - {exits}",
+	"на полу":                "on the floor",
+	"нет такого игрока - %s": "no such player - %s",
+	"нет пути в %s":          "no way to %s",
+	"путь заблокирован":      "the way is blocked",
+
+	// предметы
+	"некуда класть":                    "nowhere to put it",
+	"нет такого":                       "there is no such thing",
+	"больше некуда класть":             "no more room",
+	"предмет добавлен в инвентарь: %s": "added to inventory: %s",
+	"нельзя надеть - %s":               "cannot wear - %s",
+	"вы надели: %s":                    "you put on: %s",
+	"нет предмета в инвентаре - %s":    "not in inventory - %s",
+	NothingNeed:        "nothing needed",
+	NothingUse:         "nothing to use it on",
+	DeniedMsg:          "it doesn't work",
+	"заперто - %s":     "locked - %s",
+	"не подходит - %s": "does not fit - %s",
+	"с этой стороны не открыть":     "cannot be opened from this side",
+	"с этой стороны не закрыть":     "cannot be locked from this side",
+	"здесь нет кодового замка":      "there is no code lock here",
+	"неверный код":                  "wrong code",
+	"уже заперто":                   "already locked",
+	"не запирается - %s":            "cannot be locked - %s",
+	"нечем запереть - %s":           "nothing to lock it with - %s",
+	"заперто":                       "locked",
+	"какой код и куда ввести?":      "enter what code where?",
+	"что закрыть?":                  "close what?",
+	"ввести <код> <куда>":           "enter <code> <where>",
+	"закрыть <что>":                 "close <what>",
+	"здесь есть: %s":                "here: %s",
+	"здесь нет - %s":                "not here - %s",
+	"вы ни с кем не разговариваете": "you are not talking to anyone",
+	"нет такого варианта - %s":      "no such answer - %s",
+	"вы отдали: %s":                 "you gave away: %s",
+	"разговор окончен":              "the conversation is over",
+	"%s ответы: %s":                 "%s answers: %s",
+	"вам некуда положить, %s теперь лежит рядом": "you have no room, %s is lying next to you",
+	"с кем поговорить?":                          "talk to whom?",
+	"что ответить?":                              "answer what?",
+	"поговорить <с кем>":                         "talk <to whom>",
+	"ответить <номер>":                           "answer <number>",
+	"сейчас %s, ход %d":                          "it is %s, turn %d",
+	"ход %d":                                     "turn %d",
+	"открыто":                                    "opened",
+	"у вас ничего нет":                           "you have nothing",
+	"надето: %s":                                 "wearing: %s",
+	"в инвентаре %s: %s":                         "carrying %s: %s",
+	"инвентарь пуст":                             "inventory is empty",
+	"%s - ничего особенного":                     "%s - nothing special",
+	"сначала выложите вещи - %s":                 "drop your things first - %s",
+	"вы выложили: %s":                            "you dropped: %s",
+	"вы сделали: %s":                             "you made: %s",
+	"уже есть - %s":                              "already exists - %s",
+	"здесь не получится":                         "that won't work here",
+
+	// мебель
+	"не открывается - %s": "does not open - %s",
+	"уже открыто - %s":    "already open - %s",
+	"вы открыли: %s":      "you opened: %s",
+	"там пусто":           "it is empty",
+	"вы закрыли: %s":      "you closed: %s",
+	"уже закрыто - %s":    "already closed - %s",
+	"закрыто - %s":        "closed - %s",
+	"что открыть?":        "open what?",
+	"открыть <что>":       "open <what>",
+
+	// карта
+	"* - вы здесь, x - заперто, ? - не исследовано": "* - you are here, x - locked, ? - unexplored",
+
+	// общение
+	"%s говорит: %s":             "%s says: %s",
+	"%s кричит: %s":              "%s shouts: %s",
+	"%s кричит где-то рядом: %s": "%s shouts somewhere nearby: %s",
+	"вы сказали: %s":             "you said: %s",
+	"вы крикнули: %s":            "you shouted: %s",
+	"%s уходит в %s":             "%s leaves for %s",
+	"%s входит":                  "%s comes in",
+	"что сказать?":               "say what?",
+	"что крикнуть?":              "shout what?",
+	"сказать <текст>":            "say <text>",
+	"крикнуть <текст>":           "shout <text>",
+
+	// задания
+	"заданий нет": "no quests",
+	"задания: %s": "quests: %s",
+
+	// команды
+	UnknownCommandMsg: "unknown command",
+	"%s, возможно, вы имели в виду: %s": "%s, did you mean: %s",
+	"доступные команды: %s":             "available commands: %s",
+	"куда идти?":                        "where to go?",
+	"что взять?":                        "take what?",
+	"что надеть?":                       "wear what?",
+	"что выложить?":                     "drop what?",
+	"что осмотреть?":                    "examine what?",
+	"что и к чему применить?":           "use what on what?",
+	"идти <куда>":                       "go <where>",
+	"взять <что>":                       "take <what>",
+	"надеть <что>":                      "wear <what>",
+	"выложить <что>":                    "drop <what>",
+	"осмотреть <что>":                   "examine <what>",
+	"применить <что> <к чему>":          "use <what> <on what>",
+	"сохранить [имя]":                   "save [name]",
+	"загрузить [имя]":                   "load [name]",
+	"язык [ru|en]":                      "language [ru|en]",
+	"язык: %s":                          "language: %s",
+	"язык: %s, доступны: %s":            "language: %s, available: %s",
+	"нет такого языка - %s":             "no such language - %s",
+
+	// история
+	"нечего отменять":  "nothing to undo",
+	"нечего повторять": "nothing to redo",
+	"нельзя отменить: после вас мир уже изменился": "cannot undo: the world has changed since",
+	"нельзя повторить: мир уже изменился":          "cannot redo: the world has changed",
+	"отменено: %s":                    "undone: %s",
+	"повторено: %s":                   "redone: %s",
+	"история [имя]":                   "history [name]",
+	"история записана":                "history written",
+	"не удалось записать историю: %s": "could not write history: %s",
+
+	// сохранения
+	"недопустимое имя сохранения - %s": "invalid save name - %s",
+	"не удалось сохранить: %s":         "could not save: %s",
+	"игра сохранена":                   "game saved",
+	"нет такого сохранения":            "no such save",
+	"не удалось загрузить: %s":         "could not load: %s",
+	"игра загружена":                   "game loaded",
+
+	"команда доступна только в локальной игре": "this command is only available in a local game",
+}
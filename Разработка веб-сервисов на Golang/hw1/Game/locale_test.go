@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEnglishSession(t *testing.T) {
+	initGame()
+	s, err := world.Join("john")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err = s.SetLocale("fr"); !errors.Is(err, errUnknownLocale) {
+		t.Fatalf("expected errUnknownLocale, got %v", err)
+	}
+	if err = s.SetLocale("en"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	cases := []gameCase{
+		{1, "go to коридор", "ничего интересного. exits - кухня, комната, улица"},
+		{2, "go", "where to go?"},
+		{3, "go кладовка", "no way to кладовка"},
+		{4, "take ключи", "nowhere to put it"},
+		{5, "идти комната", "ты в своей комнате. exits - коридор"}, // русские глаголы тоже работают
+		{6, "wear рюкзак", "you put on: рюкзак"},
+		{7, "take ключи", "added to inventory: ключи"},
+		{8, "inventory", "wearing: рюкзак. carrying 1 item: ключи"},
+		{9, "take конспекты", "added to inventory: конспекты"},
+		{10, "inv", "wearing: рюкзак. carrying 2 items: ключи, конспекты"},
+		{11, "go коридор", "ничего интересного. exits - кухня, комната, улица"},
+		{12, "use ключи on дверь", "дверь открыта"}, // тексты мира не переводятся
+		{13, "use ключи дверь", "nothing to use it on"},
+		{14, "go кухня", "кухня, ничего интересного. exits - коридор"},
+		{15, "look", "ты находишься на кухне, на столе: чай, надо идти в универ. можно пройти - коридор"}, // цель — тоже текст мира, связки в ней не переводятся
+		{16, "dance", "unknown command"},
+		{17, "invetory", "unknown command, did you mean: inventory"},
+		{18, "language ru", "язык: русский"},
+		{19, "инвентарь", "надето: рюкзак. в инвентаре 2 предмета: ключи, конспекты"},
+	}
+	for _, item := range cases {
+		if answer := s.Handle(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+
+	// язык у каждого игрока свой
+	if answer := handleCommand("go коридор"); answer != UnknownCommandMsg {
+		t.Errorf("default player: unexpected answer %q", answer)
+	}
+}
+
+func TestLocaleCommand(t *testing.T) {
+	initGame()
+	cases := []gameCase{
+		{1, "язык", "язык: русский, доступны: en, ru"},
+		{2, "язык de", "нет такого языка - de"},
+		{3, "язык en", "language: english"},
+		{4, "quests", "quests: в универ (0/2): [ ] собрать рюкзак, [ ] идти в универ"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+
+	help := handleCommand("help")
+	if !strings.HasPrefix(help, "available commands: look, go <where>, take <what>") || !strings.Contains(help, ", language [ru|en], help") {
+		t.Errorf("unexpected help %q", help)
+	}
+}
+
+func TestLocaleSurvivesSnapshot(t *testing.T) {
+	initGame()
+	handleCommand("язык en")
+	buf := &bytes.Buffer{}
+	if err := world.Save(buf); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	initGame()
+	if err := world.Load(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if answer := handleCommand("inventory"); answer != "you have nothing" {
+		t.Errorf("unexpected answer %q", answer)
+	}
+
+	bad := strings.Replace(buf.String(), `"locale": "en"`, `"locale": "xx"`, 1)
+	if err := world.Load(strings.NewReader(bad)); !errors.Is(err, errUnknownLocale) || !errors.Is(err, errSnapshotMismatch) {
+		t.Errorf("expected errUnknownLocale, got %v", err)
+	}
+}
+
+func TestPlural(t *testing.T) {
+	ru, en := locales["ru"], locales["en"]
+	cases := []struct {
+		l    *Locale
+		n    int
+		want string
+	}{
+		{ru, 1, "1 предмет"},
+		{ru, 2, "2 предмета"},
+		{ru, 5, "5 предметов"},
+		{ru, 11, "11 предметов"},
+		{ru, 12, "12 предметов"},
+		{ru, 21, "21 предмет"},
+		{ru, 24, "24 предмета"},
+		{ru, 0, "0 предметов"},
+		{en, 1, "1 item"},
+		{en, 0, "0 items"},
+		{en, 3, "3 items"},
+	}
+	for _, c := range cases {
+		if got := c.l.plural(c.n, "предмет"); got != c.want {
+			t.Errorf("%s: plural(%d) = %q, want %q", c.l.name, c.n, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// --- Замки ---
+
+// defaultLockName — как игрок называет замок, если в описании мира имя не задано
+const defaultLockName = "дверь"
+
+var (
+	errDuplicateLock = errors.New("замок объявлен повторно")
+	errUnknownLock   = errors.New("неизвестный замок")
+	errBadLock       = errors.New("некорректный замок")
+)
+
+// Lock — замок на выходе из комнаты или на предмете-контейнере.
+// Замок из каталога может висеть на нескольких выходах (дверь с двух сторон),
+// тогда открывается и запирается он для всех сразу.
+type Lock struct {
+	name      string   // как замок называют в командах: "дверь", "решётка"
+	locked    bool     // заперт
+	keys      []string // предметы, которые его открывают
+	consume   bool     // ключ пропадает после открытия
+	code      string   // код для "ввести <код> <замок>"
+	closable  bool     // можно запереть снова командой "закрыть"
+	autoLock  bool     // запирается сам, когда через него прошли
+	lockMsg   string   // ответ на попытку пройти через запертый замок
+	unlockMsg string
+	closeMsg  string
+}
+
+// accepts сообщает, открывается ли замок предметом item
+func (l *Lock) accepts(item string) bool {
+	for _, k := range l.keys {
+		if k == item {
+			return true
+		}
+	}
+	return false
+}
+
+// LockDef описывает замок в файле мира. На выходе или предмете вместо объекта
+// можно указать строку — id замока из каталога WorldDef.Locks.
+type LockDef struct {
+	ID        string   `json:"id,omitempty"`   // только в каталоге
+	Name      string   `json:"name,omitempty"` // по умолчанию defaultLockName
+	Locked    bool     `json:"locked,omitempty"`
+	Keys      []string `json:"keys,omitempty"`
+	Consume   bool     `json:"consume,omitempty"`
+	Code      string   `json:"code,omitempty"`
+	Closable  bool     `json:"closable,omitempty"`
+	AutoLock  bool     `json:"autoLock,omitempty"`
+	LockMsg   string   `json:"lockMsg,omitempty"`
+	UnlockMsg string   `json:"unlockMsg,omitempty"`
+	CloseMsg  string   `json:"closeMsg,omitempty"`
+}
+
+// UnmarshalJSON принимает как "id" замка из каталога, так и полный объект
+func (d *LockDef) UnmarshalJSON(b []byte) error {
+	var id string
+	if err := json.Unmarshal(b, &id); err == nil {
+		*d = LockDef{ID: id}
+		return nil
+	}
+	type plain LockDef
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*plain)(d))
+}
+
+// isRef — замок задан только ссылкой на каталог
+func (d *LockDef) isRef() bool {
+	return d.ID != "" && d.Name == "" && !d.Locked && len(d.Keys) == 0 && d.Code == "" &&
+		!d.Closable && !d.AutoLock && !d.Consume && d.LockMsg == "" && d.UnlockMsg == "" && d.CloseMsg == ""
+}
+
+func (d *LockDef) lock() *Lock {
+	name := d.Name
+	if name == "" {
+		name = defaultLockName
+	}
+	return &Lock{
+		name:      name,
+		locked:    d.Locked,
+		keys:      d.Keys,
+		consume:   d.Consume,
+		code:      d.Code,
+		closable:  d.Closable,
+		autoLock:  d.AutoLock,
+		lockMsg:   d.LockMsg,
+		unlockMsg: d.UnlockMsg,
+		closeMsg:  d.CloseMsg,
+	}
+}
+
+// lockDef — замок выхода. Старые поля locked/unlockItem/lockMsg/unlockMsg
+// описывают замок с одним ключом по имени defaultLockName.
+func (pd PathDef) lockDef() *LockDef {
+	if pd.Lock != nil {
+		return pd.Lock
+	}
+	if !pd.Locked && pd.UnlockItem == "" && pd.LockMsg == "" && pd.UnlockMsg == "" {
+		return nil
+	}
+	ld := &LockDef{Locked: pd.Locked, LockMsg: pd.LockMsg, UnlockMsg: pd.UnlockMsg}
+	if pd.UnlockItem != "" {
+		ld.Keys = []string{pd.UnlockItem}
+	}
+	return ld
+}
+
+// buildLock возвращает общий замок из каталога или новый замок по описанию
+func (w *World) buildLock(ld *LockDef) *Lock {
+	if ld == nil {
+		return nil
+	}
+	if ld.isRef() {
+		return w.locks[ld.ID]
+	}
+	return ld.lock()
+}
+
+// validateLocks проверяет каталог замков и замки на выходах и предметах
+func (d *WorldDef) validateLocks() []error {
+	var errs []error
+	catalog := make(map[string]bool, len(d.Locks))
+	for i := range d.Locks {
+		ld := &d.Locks[i]
+		switch {
+		case ld.ID == "":
+			errs = append(errs, fmt.Errorf("каталог замков: %w", errEmptyName))
+		case catalog[ld.ID]:
+			errs = append(errs, fmt.Errorf("замок %q: %w", ld.ID, errDuplicateLock))
+		}
+		catalog[ld.ID] = true
+	}
+	for _, ld := range d.Locks {
+		for _, key := range ld.Keys {
+			if !d.hasItem(key) {
+				errs = append(errs, fmt.Errorf("замок %q: %w: нет предмета %q", ld.ID, errBadLock, key))
+			}
+		}
+	}
+
+	for _, rd := range d.Rooms {
+		errs = append(errs, d.validateRoomLocks(catalog, rd)...)
+	}
+	for _, it := range d.Items {
+		if it.Lock != nil {
+			errs = append(errs, d.checkLock(catalog, fmt.Sprintf("предмет %q", it.Name), it.Lock)...)
+		}
+	}
+	return errs
+}
+
+// validateRoomLocks проверяет замки на выходах, предметах и мебели комнаты
+func (d *WorldDef) validateRoomLocks(catalog map[string]bool, rd RoomDef) []error {
+	var errs []error
+	for _, pd := range rd.Paths {
+		where := fmt.Sprintf("комната %q, путь %q", rd.Name, pd.name())
+		if pd.Lock != nil && (pd.Locked || pd.UnlockItem != "" || pd.LockMsg != "" || pd.UnlockMsg != "") {
+			errs = append(errs, fmt.Errorf("%s: %w: lock вместе с locked/unlockItem", where, errBadLock))
+		}
+		ld := pd.lockDef()
+		if ld == nil {
+			if pd.OneWay {
+				errs = append(errs, fmt.Errorf("%s: %w: oneWay без замка", where, errBadLock))
+			}
+			continue
+		}
+		errs = append(errs, d.checkLock(catalog, where, ld)...)
+	}
+	for _, it := range rd.allItems() {
+		if it.Lock != nil {
+			errs = append(errs, d.checkLock(catalog, fmt.Sprintf("комната %q, предмет %q", rd.Name, it.Name), it.Lock)...)
+		}
+	}
+	for _, cd := range rd.Containers {
+		if cd.Lock != nil {
+			errs = append(errs, d.checkLock(catalog, fmt.Sprintf("комната %q, мебель %q", rd.Name, cd.Name), cd.Lock)...)
+		}
+	}
+	return errs
+}
+
+// checkLock проверяет ссылку на каталог или ключи замка ld
+func (d *WorldDef) checkLock(catalog map[string]bool, where string, ld *LockDef) []error {
+	if ld.isRef() {
+		if !catalog[ld.ID] {
+			return []error{fmt.Errorf("%s: %w %q", where, errUnknownLock, ld.ID)}
+		}
+		return nil
+	}
+	var errs []error
+	for _, key := range ld.Keys {
+		if !d.hasItem(key) {
+			errs = append(errs, fmt.Errorf("%s: %w: нет предмета %q", where, errBadLock, key))
+		}
+	}
+	return errs
+}
+
+// --- Поиск замка по словам игрока ---
+
+// findLock находит замок, о котором говорит игрок: по имени выхода ("улица"),
+// по имени предмета или мебели с замком ("сундук", "шкаф") или по имени замка ("дверь").
+// Если замков с таким именем несколько, берётся первый, для которого
+// выполняется prefer, а если таких нет — просто первый.
+// Второй результат — выход, на котором висит замок, nil для предметов.
+func (w *World) findLock(p *Player, target string, prefer func(*Lock) bool) (*Lock, *Path) {
+	r := p.room
+	if path, ok := r.paths[target]; ok && path.lock != nil {
+		return path.lock, path
+	}
+	if it := p.reachable(target); it != nil && it.lock != nil {
+		return it.lock, nil
+	}
+	if c := r.container(target); c != nil && c.lock != nil {
+		return c.lock, nil
+	}
+
+	type site struct {
+		lock *Lock
+		path *Path
+	}
+	var found []site
+	for _, name := range r.exits {
+		if path := r.paths[name]; path.lock != nil && path.lock.name == target {
+			found = append(found, site{path.lock, path})
+		}
+	}
+	for _, items := range []map[string]*Item{r.visible(), p.inventory, p.worn} {
+		for _, name := range sortedKeys(items) {
+			if l := items[name].lock; l != nil && l.name == target {
+				found = append(found, site{l, nil})
+			}
+		}
+	}
+	for _, c := range r.containers {
+		if c.lock != nil && c.lock.name == target {
+			found = append(found, site{c.lock, nil})
+		}
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+	for _, s := range found {
+		if prefer(s.lock) {
+			return s.lock, s.path
+		}
+	}
+	return found[0].lock, found[0].path
+}
+
+// reachable — предмет, который игрок видит в комнате, или предмет у игрока
+func (p *Player) reachable(name string) *Item {
+	if it, _ := p.room.find(name); it != nil {
+		return it
+	}
+	for _, items := range []map[string]*Item{p.inventory, p.worn} {
+		if it, ok := items[name]; ok {
+			return it
+		}
+	}
+	return nil
+}
+
+// unlockWith открывает замок предметом it: единственный путь, которым
+// "применить" открывает двери, решётки и сундуки. ok == false, если замка нет.
+func (w *World) unlockWith(p *Player, it *Item, target string) (answer string, ok bool) {
+	l, path := w.findLock(p, target, func(l *Lock) bool { return l.locked && l.accepts(it.name) })
+	switch {
+	case l == nil:
+		return p.tr(NothingUse), false
+	case path != nil && path.oneWay:
+		return p.tr("с этой стороны не открыть"), true
+	case !l.locked && l.name == target:
+		// по виду ("дверь") ищутся только запертые замки, как в исходной игре
+		return p.tr(NothingUse), false
+	case !l.locked:
+		return p.tr(NothingNeed), true
+	case !l.accepts(it.name):
+		return p.tr("не подходит - %s", it.name), true
+	}
+	l.locked = false
+	if l.consume {
+		delete(p.inventory, it.name)
+	}
+	return p.tr(unlockedMsg(l)), true
+}
+
+func unlockedMsg(l *Lock) string {
+	if l.unlockMsg != "" {
+		return l.unlockMsg
+	}
+	return "открыто"
+}
+
+// --- Команды "ввести" и "закрыть" ---
+
+func (w *World) handleCode(p *Player, args []string) string {
+	code, target := args[0], args[1]
+	l, path := w.findLock(p, target, func(l *Lock) bool { return l.locked && l.code != "" })
+	switch {
+	case l == nil:
+		return p.tr("нет такого")
+	case path != nil && path.oneWay:
+		return p.tr("с этой стороны не открыть")
+	case l.code == "":
+		return p.tr("здесь нет кодового замка")
+	case !l.locked:
+		return p.tr(NothingNeed)
+	case code != l.code:
+		return p.tr("неверный код")
+	}
+	l.locked = false
+	return p.tr(unlockedMsg(l))
+}
+
+func (w *World) handleClose(p *Player, args []string) string {
+	target := args[0]
+	if answer, ok := w.closeContainer(p, target); ok {
+		return answer
+	}
+	l, path := w.findLock(p, target, func(l *Lock) bool { return !l.locked && l.closable })
+	switch {
+	case l == nil:
+		return p.tr("нет такого")
+	case path != nil && path.oneWay:
+		return p.tr("с этой стороны не закрыть")
+	case l.locked:
+		return p.tr("уже заперто")
+	case !l.closable:
+		return p.tr("не запирается - %s", target)
+	case l.code == "" && len(l.keys) > 0 && !p.hasAny(l.keys):
+		return p.tr("нечем запереть - %s", target)
+	}
+	l.locked = true
+	if l.closeMsg != "" {
+		return p.tr(l.closeMsg)
+	}
+	return p.tr("заперто")
+}
+
+// hasAny сообщает, есть ли у игрока хоть один из предметов
+func (p *Player) hasAny(names []string) bool {
+	for _, name := range names {
+		if p.inventory[name] != nil || p.worn[name] != nil {
+			return true
+		}
+	}
+	return false
+}
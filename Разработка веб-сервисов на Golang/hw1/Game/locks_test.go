@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const locksTestWorld = `{
+	"start": "холл",
+	"items": [
+		{"name": "сумка", "wearable": true, "capacity": 5},
+		{"name": "отмычка"},
+		{"name": "ключ"},
+		{"name": "пропуск"},
+		{"name": "ранец", "wearable": true, "capacity": 2, "lock": {"name": "застёжка", "locked": true, "keys": ["ключ"], "unlockMsg": "застёжка расстёгнута"}}
+	],
+	"locks": [
+		{"id": "люк", "name": "люк", "autoLock": true, "closeMsg": "люк захлопнулся"}
+	],
+	"rooms": [
+		{
+			"name": "холл",
+			"description": "в холле",
+			"items": ["сумка", "отмычка", "ключ", "пропуск", "ранец"],
+			"paths": [
+				{"to": "сейф", "lock": {"name": "сейф", "locked": true, "code": "1234", "closable": true, "lockMsg": "сейф заперт"}},
+				{"to": "склад", "lock": {"locked": true, "keys": ["ключ", "отмычка"], "closable": true, "lockMsg": "склад заперт"}},
+				{"to": "офис", "lock": {"name": "турникет", "locked": true, "keys": ["пропуск"], "consume": true, "unlockMsg": "турникет забрал пропуск"}}
+			]
+		},
+		{"name": "сейф", "description": "в сейфе", "paths": [{"to": "холл"}]},
+		{"name": "склад", "description": "на складе", "paths": [{"to": "холл"}, {"name": "подвал", "to": "подвал", "lock": "люк"}]},
+		{"name": "офис", "description": "в офисе", "paths": [{"to": "холл"}]},
+		{"name": "подвал", "description": "в подвале", "paths": [{"name": "наверх", "to": "склад", "lock": "люк", "oneWay": true}]}
+	]
+}`
+
+func TestLocks(t *testing.T) {
+	if err := initGameFrom(strings.NewReader(locksTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	cases := []gameCase{
+		{1, "надеть ранец", "заперто - ранец"},
+		{2, "надеть сумка", "вы надели: сумка"},
+		{3, "взять ключ", "предмет добавлен в инвентарь: ключ"},
+		{4, "применить ключ застёжка", "застёжка расстёгнута"}, // замок на предмете
+		{5, "надеть ранец", "вы надели: ранец"},
+
+		// кодовый замок
+		{6, "идти сейф", "сейф заперт"},
+		{7, "ввести 0000 сейф", "неверный код"},
+		{8, "ввести 1234 сейфа", "открыто"},
+		{9, "ввести 1234 сейф", "ничего не требуется"},
+		{10, "ввести 1234 дверь", "здесь нет кодового замка"},
+		{11, "закрыть сейф", "заперто"},
+		{12, "закрыть сейф", "уже заперто"},
+
+		// несколько ключей и повторное запирание
+		{13, "взять отмычка", "предмет добавлен в инвентарь: отмычка"},
+		{14, "применить отмычка двери", "открыто"},
+		{15, "выложить ключ", "вы выложили: ключ"},
+		{16, "закрыть дверь", "заперто"}, // отмычка тоже подходит
+		{17, "применить ключ дверь", "нет предмета в инвентаре - ключ"},
+		{18, "выложить отмычка", "вы выложили: отмычка"},
+		{19, "применить пропуск склад", "нет предмета в инвентаре - пропуск"},
+		{20, "взять пропуск", "предмет добавлен в инвентарь: пропуск"},
+		{21, "применить пропуск склад", "не подходит - пропуск"},
+		{22, "закрыть турникет", "уже заперто"},
+
+		// ключ, который пропадает
+		{23, "применить пропуск турникет", "турникет забрал пропуск"},
+		{24, "инвентарь", "надето: ранец, сумка. инвентарь пуст"},
+		{25, "закрыть турникет", "не запирается - турникет"},
+		{26, "идти офис", "в офисе. можно пройти - холл"},
+
+		// люк захлопывается и снизу не открывается
+		{27, "идти холл", "в холле. можно пройти - сейф, склад, офис"},
+		{28, "взять отмычка", "предмет добавлен в инвентарь: отмычка"},
+		{29, "применить отмычка склад", "открыто"},
+		{30, "идти склад", "на складе. можно пройти - холл, подвал"},
+		{31, "идти подвал", "в подвале. можно пройти - наверх. люк захлопнулся"},
+		{32, "идти наверх", "путь заблокирован"},
+		{33, "применить отмычка люк", "с этой стороны не открыть"},
+		{34, "закрыть люк", "с этой стороны не закрыть"},
+		{35, "ввести 1 люк", "с этой стороны не открыть"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
+
+func TestUseAnswersInPlayerLanguage(t *testing.T) {
+	if err := initGameFrom(strings.NewReader(locksTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	cases := []gameCase{
+		{1, "язык en", "language: english"},
+		{2, "wear сумка", "you put on: сумка"},
+		{3, "take пропуск", "added to inventory: пропуск"},
+		{4, "use пропуск склад", "does not fit - пропуск"},
+		{5, "use пропуск турникет", "турникет забрал пропуск"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
+
+func TestRelockDefaultDoor(t *testing.T) {
+	initGame()
+	cases := []gameCase{
+		{1, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{2, "закрыть дверь", "уже заперто"},
+		{3, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{4, "надеть рюкзак", "вы надели: рюкзак"},
+		{5, "взять ключи", "предмет добавлен в инвентарь: ключи"},
+		{6, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{7, "применить ключи улица", "не к чему применить"}, // ключи подходят только к двери
+		{8, "применить ключи дверь", "дверь открыта"},
+		{9, "применить ключи дверь", "не к чему применить"}, // по виду ищутся только запертые замки
+		{10, "закрыть двери", "дверь заперта"},
+		{11, "идти улица", "дверь закрыта"},
+		{12, "выложить ключи", "вы выложили: ключи"},
+		{13, "закрыть", "что закрыть?"},
+		{14, "ввести 1234", "какой код и куда ввести?"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
+
+func TestItemLockSnapshot(t *testing.T) {
+	if err := initGameFrom(strings.NewReader(locksTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+	buf := &bytes.Buffer{}
+	if err := world.Save(buf); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	world.items["ранец"].lock.locked = false
+	if err := world.Load(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !world.items["ранец"].lock.locked {
+		t.Errorf("item lock state was not restored")
+	}
+
+	bad := strings.Replace(buf.String(), `"ранец"`, `"сумка"`, 1)
+	if err := world.Load(strings.NewReader(bad)); !errors.Is(err, errSnapshotMismatch) {
+		t.Errorf("expected errSnapshotMismatch, got %v", err)
+	}
+}
+
+func TestLockValidation(t *testing.T) {
+	cases := []struct {
+		src  string
+		want error
+	}{
+		{`{"start": "а", "rooms": [{"name": "а", "paths": [{"to": "а", "lock": "нет"}]}]}`, errUnknownLock},
+		{`{"start": "а", "locks": [{"id": "л"}, {"id": "л"}], "rooms": [{"name": "а"}]}`, errDuplicateLock},
+		{`{"start": "а", "locks": [{"name": "л"}], "rooms": [{"name": "а"}]}`, errEmptyName},
+		{`{"start": "а", "rooms": [{"name": "а", "paths": [{"to": "а", "lock": {"keys": ["нет"]}}]}]}`, errBadLock},
+		{`{"start": "а", "rooms": [{"name": "а", "paths": [{"to": "а", "oneWay": true}]}]}`, errBadLock},
+		{`{"start": "а", "rooms": [{"name": "а", "paths": [{"to": "а", "locked": true, "lock": {"locked": true}}]}]}`, errBadLock},
+	}
+	for i, c := range cases {
+		def, err := parseWorldDef(strings.NewReader(c.src))
+		if err != nil {
+			t.Fatalf("case %d: parse: %v", i+1, err)
+		}
+		if err := def.validate(); !errors.Is(err, c.want) {
+			t.Errorf("case %d: expected %v, got %v", i+1, c.want, err)
+		}
+	}
+}
@@ -1,402 +1,397 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"sort"
+	"net"
+	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
 // --- Типы модели мира ---
 
 // Path моделирует путь (выход) из комнаты
 type Path struct {
-	to         *Room
-	locked     bool
-	unlockItem string // предмет, которым можно открыть (например "ключи")
-	lockMsg    string // сообщение если путь заблокирован
-	unlockMsg  string // сообщение при успешном открытии
+	to       *Room
+	lock     *Lock // nil — на пути нет двери
+	oneWay   bool  // замок с этой стороны не открыть и не запереть
+	triggers []*Trigger
+}
+
+// locked сообщает, заперт ли путь
+func (p *Path) locked() bool {
+	return p.lock != nil && p.lock.locked
+}
+
+// setLocked запирает или отпирает путь. Путь без двери получает
+// простой замок без ключей, его открывают только триггеры.
+func (p *Path) setLocked(locked bool) {
+	if p.lock == nil {
+		p.lock = &Lock{name: defaultLockName}
+	}
+	p.lock.locked = locked
 }
 
 // Room представляет комнату
 type Room struct {
 	name        string
 	description string
-	items       map[string]bool
+	items       map[string]*Item // предметы на полу
+	containers  []*Container     // мебель в порядке объявления
 	paths       map[string]*Path
-
-	// Опциональные хуки:
-	lookFunc    func(w *World, r *Room) string
-	useFunc     func(w *World, r *Room, item, target string) string // сначала пробуем этот хук
-	// onEnterFunc func(w *World, from *Room) string                  // вызывается при входе
+	exits       []string // имена выходов в порядке вывода, см. orderExits
+	npcs        map[string]*NPC
+	byTime      map[string]string // описание комнаты в разное время суток
+	look        string            // шаблон ответа на "осмотреться", см. render
+	lookEmpty   string            // шаблон, когда в комнате не видно ни одного предмета
+	commands    *commandRegistry  // команды, доступные только в этой комнате
+	triggers    []*Trigger        // реакции на события в комнате (вход, выход, осмотр...)
+	pos         [2]int            // клетка на карте, см. layout
+
+	// Опциональный хук:
+	lookFunc func(w *World, p *Player, r *Room) string
 }
 
 // Player представляет игрока
 type Player struct {
-	room        *Room
-	inventory   map[string]bool
-	hasBackpack bool
+	id        string
+	online    bool // к игроку подключена сессия (или это игрок по умолчанию)
+	room      *Room
+	inventory map[string]*Item
+	worn      map[string]*Item  // надетые предметы, контейнеры среди них дают место в инвентаре
+	visited   map[string]bool   // комнаты, где игрок побывал
+	locale    *Locale           // язык сообщений и команд, nil — defaultLocale
+	dialogs   map[string]string // персонаж -> реплика, на которой остановился разговор
+	talking   string            // с кем игрок сейчас разговаривает
+	outbox    *outbox           // сообщения от других игроков, см. chat.go
+	journal   []journalEntry    // команды игрока с ответами, см. history.go
 }
 
-// World представляет игровой мир
+// World представляет игровой мир, общий для всех игроков
 type World struct {
-	rooms map[string]*Room
-	player *Player
+	// mu защищает комнаты и игроков: команды разных игроков выполняются по очереди
+	mu       sync.Mutex
+	rooms    map[string]*Room
+	items    map[string]*Item      // все предметы мира по имени
+	locks    map[string]*Lock      // общие замки из каталога по id
+	homes    map[string]*Container // где предмет лежал в начале игры, для старых снимков
+	start    *Room
+	players  map[string]*Player
+	quests   []*Quest
+	recipes  []*Recipe
+	commands *commandRegistry
+	handlers map[EventKind][]EventHandler // подписчики на события из кода
+	saveDir  string                       // каталог для команд "сохранить" и "загрузить"
+	// remoteSaves — "сохранить", "загрузить" и "история" доступны всем игрокам,
+	// а не только игроку по умолчанию, см. Command.Local
+	remoteSaves bool
+
+	clock     Clock
+	lastTick  int // до какого хода выполнено расписание
+	dayLength int
+	dayStart  int
+	periods   []Period // по возрастанию начала
+	schedule  []*Scheduled
+
+	version int       // растёт при каждом изменении состояния, см. history.go
+	history []*change // изменения, которые можно отменить, от старых к новым
+	undone  []*change // отменённые изменения, которые можно повторить
+
+	fired []ResultEvent // реакции триггеров на текущую команду, см. Result
 }
 
-// --- Инициализация игры ---
+// --- Состояние игры ---
 
 var world *World
 
 const NothingNeed = "ничего не требуется"
 const NothingUse = "не к чему применить"
 
+// DeniedMsg — ответ, если триггер отменил действие и ничего не сказал
+const DeniedMsg = "не получается"
 
-func initGame() {
-	world = &World{
-		rooms: make(map[string]*Room),
-	}
-
-	// --- Создаем комнаты ---
-	kitchen := &Room{
-		name:        "кухня",
-		description: "кухня, ничего интересного",
-		items:       make(map[string]bool),
-		paths:       make(map[string]*Path),
-	}
-
-	corridor := &Room{
-		name:        "коридор",
-		description: "ничего интересного",
-		items:       make(map[string]bool),
-		paths:       make(map[string]*Path),
-	}
-
-	room := &Room{
-		name:        "комната",
-		description: "ты в своей комнате",
-		items:       make(map[string]bool),
-		paths:       make(map[string]*Path),
-	}
+// --- Хуки комнат ---
 
-	street := &Room{
-		name:        "улица",
-		description: "на улице весна",
-		items:       make(map[string]bool),
-		paths:       make(map[string]*Path),
-	}
-
-	// --- Предметы ---
-	kitchen.items["чай"] = true
-	room.items["ключи"] = true
-	room.items["конспекты"] = true
-	room.items["рюкзак"] = true
-
-	// --- Пути (используем Path, указываем locked для двери) ---
-	kitchen.paths["коридор"] = &Path{to: corridor}
-	// путь на улицу заблокирован — его можно открыть "ключи"
-
-	corridor.paths["кухня"] = &Path{to: kitchen}
-	corridor.paths["комната"] = &Path{to: room}
-	corridor.paths["улица"] = &Path{
-		to:         street,
-		locked:     true,
-		unlockItem: "ключи",
-		lockMsg:    "дверь закрыта",
-		unlockMsg:  "дверь открыта",
-	}
-
-	room.paths["коридор"] = &Path{to: corridor}
-	street.paths["домой"] = &Path{to: corridor}
-
-	// --- Хуки lookFunc для детерминированного описания ---
-	kitchen.lookFunc = func(w *World, r *Room) string {
-		items := getRoomItems(r)
-		paths := getRoomPaths(r)
-		if !w.player.hasBackpack {
-			return fmt.Sprintf("ты находишься на кухне, на столе: %s, надо собрать рюкзак и идти в универ. можно пройти - %s", items, paths)
-		}
-		return fmt.Sprintf("ты находишься на кухне, на столе: %s, надо идти в универ. можно пройти - %s", items, paths)
-	}
-
-	room.lookFunc = func(w *World, r *Room) string {
-		// как в вашем коде: рюкзак отдельно на стуле
-		if len(r.items) == 0 {
-			return "пустая комната. можно пройти - коридор"
-		}
-		tableItems := []string{}
-		hasBackpack := false
-		for item := range r.items {
-			if item == "рюкзак" {
-				hasBackpack = true
-			} else {
-				tableItems = append(tableItems, item)
-			}
-		}
-		sort.Strings(tableItems)
-		result := "на столе: " + strings.Join(tableItems, ", ")
-		if hasBackpack {
-			if len(tableItems) > 0 {
-				result += ", "
-			}
-			result += "на стуле: рюкзак"
-		}
-		result += ". можно пройти - коридор"
-		return result
-	}
-
-	corridor.lookFunc = func(w *World, r *Room) string {
-		return fmt.Sprintf("ничего интересного. можно пройти - %s", getRoomPaths(r))
-	}
-
-	street.lookFunc = func(w *World, r *Room) string {
-		return fmt.Sprintf("на улице весна. можно пройти - %s", getRoomPaths(r))
-	}
-
-	// --- useFunc для коридора: пытается открыть заблокированные пути если item совпадает с unlockItem ---
-	corridor.useFunc = func(w *World, r *Room, item, target string) string {
-		// пробуем сначала стандартное: если цель совпадает с именем пути — пытаемся открыть
-		if p, ok := r.paths[target]; ok {
-			if !p.locked {
-				return NothingNeed 
-
-			}
-			if item == p.unlockItem {
-				p.locked = false
-				return p.unlockMsg
-			}
-			return "не сработало"
-		}
-		return NothingUse
-	}
-
-	// --- Добавляем комнаты в мир ---
-	world.rooms["кухня"] = kitchen
-	world.rooms["коридор"] = corridor
-	world.rooms["комната"] = room
-	world.rooms["улица"] = street
-
-	// --- Создаём игрока ---
-	world.player = &Player{
-		room:        kitchen,
-		inventory:   make(map[string]bool),
-		hasBackpack: false,
-	}
+// roomHook — набор хуков, на который комната ссылается по имени из описания мира
+type roomHook struct {
+	look     func(w *World, p *Player, r *Room) string
+	commands []*Command // команды, доступные только в комнате
 }
 
+// roomHooks пуст: мебель и шаблоны осмотра описываются в файле мира,
+// хуки остаются для комнат, которым нужен код
+var roomHooks = map[string]roomHook{}
+
 // --- Вспомогательные функции для вывода ---
 
-func getRoomItems(r *Room) string {
-	items := []string{}
-	for it := range r.items {
-		items = append(items, it)
-	}
-	sort.Strings(items)
+// getRoomItems перечисляет все видимые предметы комнаты, не разделяя их по местам
+func getRoomItems(p *Player, r *Room) string {
+	items := r.visible()
 	if len(items) == 0 {
-		return "ничего"
+		return p.tr("ничего")
 	}
-	return strings.Join(items, ", ")
+	return strings.Join(sortedKeys(items), ", ")
 }
 
-// func getRoomPaths(r *Room) string {
-// 	paths := []string{}
-// 	for name := range r.paths {
-// 		paths = append(paths, name)
-// 	}
-// 	return strings.Join(paths, ", ")
-// }
-
+// getRoomPaths перечисляет выходы в порядке, заданном в описании мира
 func getRoomPaths(r *Room) string {
-	names := make([]string, 0, len(r.paths))
-	for name := range r.paths {
-		names = append(names, name)
-	}
-
-	// --- Кастомная сортировка ---
-	sort.Slice(names, func(i, j int) bool {
-		a := []rune(names[i])
-		b := []rune(names[j])
-
-		// если первые буквы разные — сортируем по алфавиту
-		if a[0] != b[0] {
-			return a[0] < b[0]
-		}
-
-		// если первые совпали — остальные в обратном порядке
-		for k := 1; k < len(a) && k < len(b); k++ {
-			if a[k] != b[k] {
-				return a[k] > b[k]
-			}
-		}
-
-		// если одно слово — префикс другого
-		return len(a) < len(b)
-	})
-
-	return strings.Join(names, ", ")
+	return strings.Join(r.exits, ", ")
 }
 
-
-
-
 // --- Обработчики команд (делегирующие) ---
 
 const UnknownCommandMsg = "неизвестная команда"
 
+// handleCommand выполняет команду от имени игрока по умолчанию
 func handleCommand(command string) string {
-	parts := strings.Fields(command)
-	if len(parts) == 0 {
-		return UnknownCommandMsg
-	}
-	cmd := parts[0]
-	args := parts[1:]
-
-	switch cmd {
-	case "осмотреться":
-		return handleLook()
-	case "идти":
-		if len(args) < 1 {
-			return "куда идти?"
-		}
-		return handleGo(args[0])
-	case "взять":
-		if len(args) < 1 {
-			return "что взять?"
-		}
-		return handleTake(args[0])
-	case "надеть":
-		if len(args) < 1 {
-			return "что надеть?"
-		}
-		return handleWear(args[0])
-	case "применить":
-		if len(args) < 2 {
-			return "что и к чему применить?"
-		}
-		return handleUse(args[0], args[1])
+	return world.Handle(defaultPlayerID, command)
+}
+
+// Handle выполняет команду от имени игрока id и возвращает текст ответа,
+// см. World.Result
+func (w *World) Handle(id, command string) string {
+	return w.Result(id, command).Text()
+}
+
+func (w *World) handleLook(p *Player, _ []string) string {
+	r := p.room
+	var answer string
+	visible := len(r.visible()) > 0
+	switch {
+	case r.lookFunc != nil:
+		answer = r.lookFunc(w, p, r)
+	case r.lookEmpty != "" && !visible:
+		answer = w.render(p, r, r.lookEmpty)
+	case r.look != "":
+		answer = w.render(p, r, r.look)
+	case visible:
+		answer = w.render(p, r, p.tr("{description}, {contents}. можно пройти - {exits}"))
 	default:
-		return UnknownCommandMsg
+		answer = w.describe(p, r)
 	}
+	msgs, _ := w.emit(&Event{Kind: EventLook, Player: p, Room: r})
+	return withEvents(withNPCs(p, r, answer), msgs)
+}
+
+// describe — описание комнаты по умолчанию, его же игрок видит при входе
+func (w *World) describe(p *Player, r *Room) string {
+	return w.render(p, r, p.tr("{description}. можно пройти - {exits}"))
 }
 
-func handleLook() string {
-	r := world.player.room
-	if r.lookFunc != nil {
-		return r.lookFunc(world, r)
+// render подставляет в шаблон {description}, {items}, {contents}, {exits} и текущую цель игрока {goal}.
+// {items} — все видимые предметы через запятую, {contents} — они же по местам.
+// Описание комнаты тоже может содержать {items}, {contents}, {exits} и {goal}.
+func (w *World) render(p *Player, r *Room, tmpl string) string {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl
 	}
-	// дефолтное описание
-	return fmt.Sprintf("%s. можно пройти - %s", r.description, getRoomPaths(r))
+	vars := []string{
+		"{items}", getRoomItems(p, r),
+		"{contents}", w.contents(p, r),
+		"{exits}", getRoomPaths(r),
+		"{goal}", w.goal(p),
+		"{time}", w.timeOfDay(),
+	}
+	desc := strings.NewReplacer(vars...).Replace(r.currentDescription(w))
+	return strings.NewReplacer(append(vars, "{description}", desc)...).Replace(tmpl)
 }
 
-func handleGo(direction string) string {
-	cur := world.player.room
-	p, exists := cur.paths[direction]
+func (w *World) handleGo(p *Player, args []string) string {
+	direction := args[0]
+	cur := p.room
+	path, exists := cur.paths[direction]
 	if !exists {
-		return "нет пути в " + direction
+		return p.tr("нет пути в %s", direction)
 	}
-	if p.locked {
-		if p.lockMsg != "" {
-			return p.lockMsg
+	if path.locked() {
+		if path.lock.lockMsg != "" {
+			return p.tr(path.lock.lockMsg)
 		}
-		return "путь заблокирован"
+		return p.tr("путь заблокирован")
 	}
 
-	world.player.room = p.to
-
-	// --- Особые случаи ---
-	if world.player.room.name == "комната" {
-		return "ты в своей комнате. можно пройти - коридор"
+	leaveMsgs, denied := w.emit(&Event{Kind: EventLeave, Player: p, Room: cur, Path: path})
+	if denied {
+		return deniedAnswer(p, leaveMsgs)
 	}
-	if world.player.room.name == "кухня" {
-		return "кухня, ничего интересного. можно пройти - коридор"
+	p.room = path.to
+	p.visited[p.room.name] = true
+	p.talking = ""
+	w.tell(cur, p, "%s уходит в %s", p.id, p.room.name)
+	w.tell(p.room, p, "%s входит", p.id)
+	enterMsgs, _ := w.emit(&Event{Kind: EventEnter, Player: p, Room: p.room, Path: path})
+	if path.lock != nil && path.lock.autoLock {
+		path.lock.locked = true
+		if path.lock.closeMsg != "" {
+			enterMsgs = append(enterMsgs, p.tr(path.lock.closeMsg))
+		}
 	}
 
-	// --- Общий случай ---
-	if world.player.room.lookFunc != nil {
-		return world.player.room.lookFunc(world, world.player.room)
+	answer := withNPCs(p, p.room, w.describe(p, p.room))
+	if len(leaveMsgs) > 0 {
+		answer = withEvents(strings.Join(leaveMsgs, ". "), []string{answer})
 	}
-	return fmt.Sprintf("%s. можно пройти - %s", world.player.room.description, getRoomPaths(world.player.room))
+	return withEvents(answer, enterMsgs)
 }
 
+// deniedAnswer — ответ на действие, отменённое триггером
+func deniedAnswer(p *Player, msgs []string) string {
+	if len(msgs) == 0 {
+		return p.tr(DeniedMsg)
+	}
+	return strings.Join(msgs, ". ")
+}
 
-
-func handleTake(item string) string {
-	if !world.player.hasBackpack {
-		return "некуда класть"
+func (w *World) handleTake(p *Player, args []string) string {
+	name := args[0]
+	capacity := p.capacity()
+	if capacity == 0 {
+		return p.tr("некуда класть")
 	}
-	cur := world.player.room
-	if _, ok := cur.items[item]; !ok {
-		return "нет такого"
+	cur := p.room
+	it, from, answer := w.locate(p, args)
+	if it == nil {
+		return answer
 	}
-	delete(cur.items, item)
-	world.player.inventory[item] = true
-	return "предмет добавлен в инвентарь: " + item
+	if len(p.inventory) >= capacity {
+		return p.tr("больше некуда класть")
+	}
+	msgs, denied := w.emit(&Event{Kind: EventTake, Player: p, Room: cur, Item: it})
+	if denied {
+		return deniedAnswer(p, msgs)
+	}
+	cur.remove(name, from)
+	p.inventory[name] = it
+	return withEvents(p.tr("предмет добавлен в инвентарь: %s", name), msgs)
 }
 
-func handleWear(item string) string {
-	// только рюкзак можно надеть (по логике игры)
-	if item != "рюкзак" {
-		return "неизвестная команда"
+func (w *World) handleWear(p *Player, args []string) string {
+	name := args[0]
+	cur := p.room
+	it, from, answer := w.locate(p, args)
+	if it == nil {
+		return answer
+	}
+	if !it.wearable {
+		return p.tr("нельзя надеть - %s", name)
 	}
-	cur := world.player.room
-	if _, exists := cur.items[item]; !exists {
-		return "нет такого"
+	if it.lock != nil && it.lock.locked {
+		return p.tr("заперто - %s", name)
 	}
-	delete(cur.items, item)
-	world.player.hasBackpack = true
-	return "вы надели: " + item
+	msgs, denied := w.emit(&Event{Kind: EventTake, Player: p, Room: cur, Item: it})
+	if denied {
+		return deniedAnswer(p, msgs)
+	}
+	cur.remove(name, from)
+	p.worn[name] = it
+	return withEvents(p.tr("вы надели: %s", name), msgs)
 }
 
-func handleUse(item, target string) string {
+func (w *World) handleUse(p *Player, args []string) string {
+	item, target := args[0], args[1]
 	// проверяем инвентарь
-	if _, ok := world.player.inventory[item]; !ok {
-		return "нет предмета в инвентаре - " + item
+	it, ok := p.inventory[item]
+	if !ok {
+		return p.tr("нет предмета в инвентаре - %s", item)
+	}
+	msgs, denied := w.emit(&Event{Kind: EventUse, Player: p, Room: p.room, Item: it, Target: target})
+	if denied {
+		return deniedAnswer(p, msgs)
+	}
+	if rc := w.recipe(item, target); rc != nil {
+		return withEvents(w.craft(p, rc, target), msgs)
 	}
-	r := world.player.room
+	answer, ok := w.applyItem(p, it, target)
+	if !ok && len(msgs) > 0 {
+		// триггер сам придал смысл применению
+		return strings.Join(msgs, ". ")
+	}
+	return withEvents(answer, msgs)
+}
 
-	// сначала локальный useFunc комнаты
-	if r.useFunc != nil {
-		res := r.useFunc(world, r, item, target)
-		if res != NothingUse && res != "не сработало" && res != NothingNeed {
-			return res
-		}
-		if res == NothingNeed {
-			return res
-		}
+// applyItem — стандартное применение предмета: открыть им замок цели.
+// Ответ уже на языке игрока; ok == false, если применить не к чему.
+func (w *World) applyItem(p *Player, it *Item, target string) (answer string, ok bool) {
+	if !it.canUseOn(target) {
+		return p.tr(NothingUse), false
 	}
+	return w.unlockWith(p, it, target)
+}
 
-	// прямое применение к пути
-	if p, ok := r.paths[target]; ok {
-		if !p.locked {
-			return NothingNeed
-		}
-		if item == p.unlockItem {
-			p.locked = false
-			if p.unlockMsg != "" {
-				return p.unlockMsg
-			}
-			return "открыто"
-		}
-		return NothingUse
+// --- Запуск ---
+
+func main() {
+	addr := flag.String("addr", ":4000", "адрес для входящих telnet-соединений")
+	httpAddr := flag.String("http", "", "адрес HTTP API, пусто — без него")
+	worldFile := flag.String("world", "", "файл с описанием мира (по умолчанию мир из задания)")
+	maxConns := flag.Int("max-conns", 100, "максимум одновременных игроков, 0 — без ограничения")
+	idle := flag.Duration("idle", 10*time.Minute, "отключать игрока после такого времени бездействия (например 10m), 0 — никогда")
+	saves := flag.String("saves", "saves", "каталог для сохранений")
+	remoteSaves := flag.Bool("remote-saves", false, "разрешить сохранение, загрузку и выгрузку истории игрокам по сети")
+	replay := flag.String("replay", "", "прогнать сценарий из файла и показать расхождения")
+	record := flag.String("record", "", "играть в консоли и записать сценарий в файл")
+	lint := flag.Bool("lint", false, "проверить, что в мир можно играть, и выйти")
+	dot := flag.String("dot", "", "вместе с -lint: записать граф комнат в файл в формате DOT")
+	tick := flag.Duration("tick", 0, "длина игрового хода в реальном времени, 0 — ход на каждую команду")
+	gen := flag.Bool("gen", false, "вывести описание сгенерированного мира и выйти")
+	seed := flag.Uint64("seed", 1, "вместе с -gen: зерно генератора")
+	rooms := flag.Int("rooms", 8, "вместе с -gen: сколько комнат")
+	flag.Parse()
+
+	var err error
+	switch {
+	case *replay != "":
+		err = runReplay(*replay, *worldFile, os.Stdout)
+	case *record != "":
+		err = runRecord(*record, *worldFile, os.Stdin, os.Stdout)
+	case *lint:
+		err = runLint(*worldFile, *dot, os.Stdout)
+	case *gen:
+		err = runGenerate(GenOptions{Seed: *seed, Rooms: *rooms}, os.Stdout)
+	default:
+		err = run(*addr, *httpAddr, *worldFile, *saves, *remoteSaves, *maxConns, *idle, *tick)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
+}
 
-	// если цель "дверь" — ищем путь с locked == true
-	if target == "дверь" {
-		for _, p := range r.paths {
-			if p.locked && item == p.unlockItem {
-				p.locked = false
-				if p.unlockMsg != "" {
-					return p.unlockMsg
-				}
-				return "открыто"
-			}
-		}
+func run(addr, httpAddr, worldFile, saves string, remoteSaves bool, maxConns int, idle, tick time.Duration) error {
+	w, err := openWorld(worldFile)
+	if err != nil {
+		return err
+	}
+	world = w
+	world.saveDir = saves
+	world.remoteSaves = remoteSaves
+	if tick > 0 {
+		world.SetClock(&WallClock{Period: tick})
 	}
 
-	return NothingUse
-}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
+	if httpAddr != "" {
+		api := &API{World: world, MaxSessions: maxConns, IdleTimeout: idle}
+		go func() {
+			if err := serveAPI(ctx, httpAddr, api); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				stop()
+			}
+		}()
+	}
 
-// --- Простой REPL (удалите/измените для тестов) ---
-func main() {
-	
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	fmt.Println("игра слушает", ln.Addr())
+	srv := &Server{World: world, MaxConns: maxConns, IdleTimeout: idle}
+	return srv.Serve(ctx, ln)
 }
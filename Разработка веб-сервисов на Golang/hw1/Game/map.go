@@ -0,0 +1,293 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// --- Карта ---
+//
+// Комнаты раскладываются по клеткам сетки один раз, при создании мира.
+// Клетку можно задать явно (RoomDef.At), остальные комнаты ставятся рядом
+// с соседями: по подсказке направления (PathDef.Dir или имя выхода вроде
+// "север"), а без неё — в первую свободную клетку вокруг.
+//
+// Игрок видит на карте только комнаты, где побывал (Player.visited),
+// и отметки "?" там, куда из них ведут выходы.
+
+var errBadLayout = errors.New("некорректное расположение")
+
+// compassDelta — смещение клетки по сторонам света, y растёт к югу
+var compassDelta = map[string][2]int{
+	"север":         {0, -1},
+	"северо-восток": {1, -1},
+	"восток":        {1, 0},
+	"юго-восток":    {1, 1},
+	"юг":            {0, 1},
+	"юго-запад":     {-1, 1},
+	"запад":         {-1, 0},
+	"северо-запад":  {-1, -1},
+}
+
+// freeOrder — куда ставить соседа без подсказки, по порядку предпочтения
+var freeOrder = []string{"восток", "юг", "запад", "север", "юго-восток", "северо-восток", "юго-запад", "северо-запад"}
+
+// validateLayout проверяет заданные клетки и подсказки направлений
+func (d *WorldDef) validateLayout() []error {
+	var errs []error
+	taken := make(map[[2]int]string)
+	for _, rd := range d.Rooms {
+		if rd.At != nil {
+			if other, ok := taken[*rd.At]; ok {
+				errs = append(errs, fmt.Errorf("комната %q: %w: клетка %v занята комнатой %q", rd.Name, errBadLayout, *rd.At, other))
+			}
+			taken[*rd.At] = rd.Name
+		}
+		for _, pd := range rd.Paths {
+			if _, ok := compassDelta[pd.Dir]; pd.Dir != "" && !ok {
+				errs = append(errs, fmt.Errorf("комната %q, путь %q: %w: неизвестное направление %q", rd.Name, pd.name(), errBadLayout, pd.Dir))
+			}
+		}
+	}
+	return errs
+}
+
+// pathDir — направление пути для раскладки: заданное явно или имя выхода
+func pathDir(pd PathDef) string {
+	if pd.Dir != "" {
+		return pd.Dir
+	}
+	if _, ok := compassDelta[pd.name()]; ok {
+		return pd.name()
+	}
+	return ""
+}
+
+// layout раскладывает комнаты по сетке обходом в ширину от стартовой
+func (w *World) layout(d *WorldDef) {
+	taken := make(map[[2]int]*Room)
+	placed := make(map[*Room]bool)
+	place := func(r *Room, at [2]int) {
+		r.pos, placed[r], taken[at] = at, true, r
+	}
+
+	// dirs[from][to] — подсказка, где to относительно from
+	dirs := make(map[*Room]map[*Room]string, len(d.Rooms))
+	for _, rd := range d.Rooms {
+		r := w.rooms[rd.Name]
+		if rd.At != nil {
+			place(r, *rd.At)
+		}
+		dirs[r] = make(map[*Room]string)
+		for _, pd := range rd.Paths {
+			if dir := pathDir(pd); dir != "" {
+				dirs[r][w.rooms[pd.To]] = dir
+			}
+		}
+	}
+	if !placed[w.start] {
+		place(w.start, nearestFree(taken, [2]int{0, 0}))
+	}
+
+	queue := []*Room{w.start}
+	seen := map[*Room]bool{w.start: true}
+	for len(queue) > 0 {
+		r := queue[0]
+		queue = queue[1:]
+		for _, name := range r.exits {
+			to := r.paths[name].to
+			if !seen[to] {
+				seen[to] = true
+				queue = append(queue, to)
+			}
+			if !placed[to] {
+				delta := neighbourDelta(dirs, taken, r, to)
+				place(to, nearestFree(taken, [2]int{r.pos[0] + delta[0], r.pos[1] + delta[1]}))
+			}
+		}
+	}
+	// комнаты, куда от старта не ведёт ни один путь, — в ближайшие свободные клетки
+	for _, name := range sortedKeys(w.rooms) {
+		if r := w.rooms[name]; !placed[r] {
+			place(r, nearestFree(taken, [2]int{0, 0}))
+		}
+	}
+}
+
+// nearestFree — ближайшая к at свободная клетка, обход квадратами вокруг неё
+func nearestFree(taken map[[2]int]*Room, at [2]int) [2]int {
+	for radius := 0; ; radius++ {
+		for dy := -radius; dy <= radius; dy++ {
+			for dx := -radius; dx <= radius; dx++ {
+				c := [2]int{at[0] + dx, at[1] + dy}
+				if taken[c] == nil {
+					return c
+				}
+			}
+		}
+	}
+}
+
+// neighbourDelta — куда от r ставить соседа to: по подсказке в любую сторону,
+// а без неё — в первую свободную клетку вокруг r
+func neighbourDelta(dirs map[*Room]map[*Room]string, taken map[[2]int]*Room, r, to *Room) [2]int {
+	if delta, ok := compassDelta[dirs[r][to]]; ok {
+		return delta
+	}
+	// обратный путь тоже подсказывает: если из to на запад, то to на востоке
+	if back, ok := compassDelta[dirs[to][r]]; ok {
+		return [2]int{-back[0], -back[1]}
+	}
+	for _, dir := range freeOrder {
+		c := compassDelta[dir]
+		if taken[[2]int{r.pos[0] + c[0], r.pos[1] + c[1]}] == nil {
+			return c
+		}
+	}
+	return [2]int{}
+}
+
+// linked сообщает, есть ли путь между a и b в любую сторону, и заперт ли он
+func linked(a, b *Room) (ok, locked bool) {
+	for _, pair := range [][2]*Room{{a, b}, {b, a}} {
+		for _, path := range pair[0].paths {
+			if path.to == pair[1] {
+				ok = true
+				locked = locked || path.locked()
+			}
+		}
+	}
+	return ok, locked
+}
+
+// --- Команда "карта" ---
+
+// mapView — то, что игрок видит на карте
+type mapView struct {
+	p     *Player
+	known map[*Room]bool // изведанные комнаты (true) и неизведанные за их выходами (false)
+	cells map[[2]int]*Room
+	width int // ширина клетки по самой длинной подписи
+
+	minX, minY, maxX, maxY int
+}
+
+func newMapView(w *World, p *Player) *mapView {
+	// на карте то, где игрок был, и неизведанные комнаты за их выходами
+	v := &mapView{p: p, known: make(map[*Room]bool)}
+	for name := range p.visited {
+		r := w.rooms[name]
+		v.known[r] = true
+		for _, path := range r.paths {
+			if !v.known[path.to] && !p.visited[path.to.name] {
+				v.known[path.to] = false
+			}
+		}
+	}
+	v.cells = make(map[[2]int]*Room, len(v.known))
+	v.minX, v.minY, v.maxX, v.maxY = p.room.pos[0], p.room.pos[1], p.room.pos[0], p.room.pos[1]
+	for r := range v.known {
+		v.cells[r.pos] = r
+		v.width = max(v.width, utf8.RuneCountInString(v.label(r)))
+		v.minX, v.maxX = min(v.minX, r.pos[0]), max(v.maxX, r.pos[0])
+		v.minY, v.maxY = min(v.minY, r.pos[1]), max(v.maxY, r.pos[1])
+	}
+	return v
+}
+
+func (v *mapView) label(r *Room) string {
+	switch {
+	case !v.known[r]:
+		return "[?]"
+	case r == v.p.room:
+		return "[*" + r.name + "]"
+	}
+	return "[" + r.name + "]"
+}
+
+// center выравнивает s по середине клетки, заполняя края left и right
+func (v *mapView) center(s, left, right string) string {
+	pad := v.width - utf8.RuneCountInString(s)
+	return strings.Repeat(left, pad/2) + s + strings.Repeat(right, pad-pad/2)
+}
+
+// connect — путь между соседними клетками, если хотя бы одна из них изведана
+func (v *mapView) connect(a, b *Room) (ok, locked bool) {
+	if a == nil || b == nil || (!v.known[a] && !v.known[b]) {
+		return false, false
+	}
+	return linked(a, b)
+}
+
+// row рисует ряд клеток y и линию связей под ним
+func (v *mapView) row(y int) (row, below string) {
+	rb, bb := &strings.Builder{}, &strings.Builder{}
+	left := " "
+	for x := v.minX; x <= v.maxX; x++ {
+		r := v.cells[[2]int{x, y}]
+		right, link := " ", "   "
+		switch ok, locked := v.connect(r, v.cells[[2]int{x + 1, y}]); {
+		case locked:
+			right, link = "-", "-x-"
+		case ok:
+			right, link = "-", "---"
+		}
+		if r == nil {
+			rb.WriteString(strings.Repeat(" ", v.width))
+		} else {
+			rb.WriteString(v.center(v.label(r), left, right))
+		}
+		rb.WriteString(link)
+		left = right
+
+		mark := " "
+		switch ok, locked := v.connect(r, v.cells[[2]int{x, y + 1}]); {
+		case locked:
+			mark = "x"
+		case ok:
+			mark = "|"
+		}
+		bb.WriteString(v.center(mark, " ", " ") + "   ")
+	}
+	return strings.TrimRight(rb.String(), " "), strings.TrimRight(bb.String(), " ")
+}
+
+// extra — пути между несоседними изведанными клетками: их не нарисовать, они перечисляются отдельно
+func (v *mapView) extra() []string {
+	var lines []string
+	for a, knownA := range v.known {
+		for b, knownB := range v.known {
+			dx, dy := b.pos[0]-a.pos[0], b.pos[1]-a.pos[1]
+			if a.name >= b.name || dx*dx+dy*dy == 1 || !knownA || !knownB {
+				continue
+			}
+			if ok, locked := linked(a, b); ok {
+				sep := " --- "
+				if locked {
+					sep = " -x- "
+				}
+				lines = append(lines, v.label(a)+sep+v.label(b))
+			}
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func (w *World) handleMap(p *Player, _ []string) string {
+	v := newMapView(w, p)
+	var lines []string
+	for y := v.minY; y <= v.maxY; y++ {
+		row, below := v.row(y)
+		lines = append(lines, row)
+		if y < v.maxY {
+			lines = append(lines, below)
+		}
+	}
+	lines = append(lines, v.extra()...)
+	lines = append(lines, p.tr("* - вы здесь, x - заперто, ? - не исследовано"))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const mapLegend = "* - вы здесь, x - заперто, ? - не исследовано"
+
+func TestMap(t *testing.T) {
+	initGame()
+	cases := []gameCase{
+		{1, "карта", "[*кухня]-----[?]\n" + mapLegend},
+		{2, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{3, "карта", strings.Join([]string{
+			" [кухня]-----[*коридор]-x----[?]",
+			"                 |",
+			"                [?]",
+			mapLegend,
+		}, "\n")},
+		{4, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{5, "надеть рюкзак", "вы надели: рюкзак"},
+		{6, "взять ключи", "предмет добавлен в инвентарь: ключи"},
+		{7, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{8, "применить ключи дверь", "дверь открыта"},
+		{9, "идти улица", "на улице весна. можно пройти - домой"},
+		{10, "карта", strings.Join([]string{
+			" [кухня]----[коридор]---[*улица]",
+			"                |",
+			"            [комната]",
+			mapLegend,
+		}, "\n")},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+
+	// у каждого игрока свой туман: новый игрок видит только кухню
+	s, err := world.Join("анна")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if answer := s.Handle("карта"); answer != "[*кухня]-----[?]\n"+mapLegend {
+		t.Errorf("new player map: got %q", answer)
+	}
+}
+
+func TestMapLayout(t *testing.T) {
+	src := `{
+		"start": "холл",
+		"rooms": [
+			{"name": "холл", "at": [0, 0], "paths": [{"name": "север", "to": "сад"}, {"to": "чулан"}, {"to": "башня"}]},
+			{"name": "сад", "paths": [{"name": "юг", "to": "холл"}]},
+			{"name": "чулан", "paths": [{"to": "холл"}]},
+			{"name": "башня", "at": [3, 0], "paths": [{"to": "холл"}]}
+		]
+	}`
+	if err := initGameFrom(strings.NewReader(src)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	want := map[string][2]int{"холл": {0, 0}, "сад": {0, -1}, "чулан": {1, 0}, "башня": {3, 0}}
+	for name, pos := range want {
+		if got := world.rooms[name].pos; got != pos {
+			t.Errorf("%s: got %v, want %v", name, got, pos)
+		}
+	}
+	for _, cmd := range []string{"идти север", "идти юг", "идти чулан", "идти холл", "идти башня", "идти холл"} {
+		handleCommand(cmd)
+	}
+	answer := handleCommand("карта")
+	wantMap := strings.Join([]string{
+		" [сад]",
+		"   |",
+		"[*холл]---[чулан]             [башня]",
+		"[башня] --- [*холл]", // путь между несоседними клетками
+		mapLegend,
+	}, "\n")
+	if answer != wantMap {
+		t.Errorf("got map\n%s\nwant\n%s", answer, wantMap)
+	}
+}
+
+func TestMapLayoutValidation(t *testing.T) {
+	src := `{"start": "а", "rooms": [
+		{"name": "а", "at": [1, 1], "paths": [{"to": "б", "dir": "вверх"}]},
+		{"name": "б", "at": [1, 1], "paths": [{"to": "а"}]}
+	]}`
+	def, err := parseWorldDef(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	errs := def.validateLayout()
+	if len(errs) != 2 || !errors.Is(errs[0], errBadLayout) || !errors.Is(errs[1], errBadLayout) {
+		t.Errorf("got %v, want two errBadLayout", errs)
+	}
+}
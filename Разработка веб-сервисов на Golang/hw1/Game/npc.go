@@ -0,0 +1,291 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// --- Персонажи и диалоги ---
+
+var (
+	errDuplicateNPC = errors.New("персонаж объявлен повторно")
+	errBadDialogue  = errors.New("некорректный диалог")
+)
+
+// NPC — персонаж в комнате. Разговор с ним — дерево реплик: игрок видит
+// реплику персонажа и выбирает ответ, ответ ведёт к следующей реплике.
+// Где остановился разговор, у каждого игрока своё (Player.dialogs).
+type NPC struct {
+	name        string
+	description string
+	start       string // реплика, с которой начинается первый разговор
+	nodes       map[string]*DialogueNode
+}
+
+// DialogueNode — реплика персонажа и варианты ответа на неё
+type DialogueNode struct {
+	text    string
+	choices []*Choice
+}
+
+// Choice — вариант ответа. Пока не выполнено условие when, вариант не виден.
+type Choice struct {
+	text   string
+	when   Condition
+	next   string   // реплика, с которой продолжится разговор
+	end    bool     // ответ заканчивает разговор
+	give   string   // персонаж отдаёт предмет
+	take   string   // персонаж забирает предмет, без него вариант не виден
+	effect *Trigger // say, unlock, lock, describe — как у триггеров
+}
+
+func (c *Choice) visible(ev *Event) bool {
+	if c.take != "" && ev.Player.inventory[c.take] == nil {
+		return false
+	}
+	return c.when.match(ev)
+}
+
+// NPCDef описывает персонажа в комнате
+type NPCDef struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Start       string        `json:"start"`
+	Nodes       []DialogueDef `json:"nodes"`
+}
+
+// DialogueDef описывает реплику персонажа
+type DialogueDef struct {
+	ID      string      `json:"id"`
+	Text    string      `json:"text"`
+	Choices []ChoiceDef `json:"choices,omitempty"`
+}
+
+// ChoiceDef описывает вариант ответа
+type ChoiceDef struct {
+	Text     string    `json:"text"`
+	If       Condition `json:"if,omitempty"`
+	Next     string    `json:"next,omitempty"`
+	End      bool      `json:"end,omitempty"`
+	Give     string    `json:"give,omitempty"`
+	Take     string    `json:"take,omitempty"`
+	Say      string    `json:"say,omitempty"`
+	Unlock   string    `json:"unlock,omitempty"`
+	Lock     string    `json:"lock,omitempty"`
+	Describe string    `json:"describe,omitempty"`
+}
+
+func (nd NPCDef) npc() *NPC {
+	n := &NPC{
+		name:        nd.Name,
+		description: nd.Description,
+		start:       nd.Start,
+		nodes:       make(map[string]*DialogueNode, len(nd.Nodes)),
+	}
+	for _, dd := range nd.Nodes {
+		node := &DialogueNode{text: dd.Text}
+		for _, cd := range dd.Choices {
+			node.choices = append(node.choices, &Choice{
+				text: cd.Text,
+				when: cd.If,
+				next: cd.Next,
+				end:  cd.End,
+				give: cd.Give,
+				take: cd.Take,
+				effect: &Trigger{
+					say:     cd.Say,
+					unlock:  cd.Unlock,
+					lock:    cd.Lock,
+					setDesc: cd.Describe,
+				},
+			})
+		}
+		n.nodes[dd.ID] = node
+	}
+	return n
+}
+
+// validateNPCs проверяет персонажей всех комнат: имена уникальны на весь мир,
+// потому что по ним хранится состояние разговоров игроков
+func (d *WorldDef) validateNPCs() []error {
+	var errs []error
+	seen := make(map[string]bool)
+	for _, rd := range d.Rooms {
+		for _, nd := range rd.NPCs {
+			prefix := fmt.Sprintf("комната %q, персонаж %q", rd.Name, nd.Name)
+			switch {
+			case nd.Name == "":
+				errs = append(errs, fmt.Errorf("комната %q, персонаж: %w", rd.Name, errEmptyName))
+				continue
+			case seen[nd.Name]:
+				errs = append(errs, fmt.Errorf("%s: %w", prefix, errDuplicateNPC))
+			}
+			seen[nd.Name] = true
+			errs = append(errs, nd.validate(prefix, d)...)
+		}
+	}
+	return errs
+}
+
+func (nd NPCDef) validate(prefix string, d *WorldDef) []error {
+	var errs []error
+	nodes := make(map[string]bool, len(nd.Nodes))
+	for _, dd := range nd.Nodes {
+		if dd.ID == "" || nodes[dd.ID] {
+			errs = append(errs, fmt.Errorf("%s: %w: пустой или повторный id реплики %q", prefix, errBadDialogue, dd.ID))
+		}
+		nodes[dd.ID] = true
+	}
+	if !nodes[nd.Start] {
+		errs = append(errs, fmt.Errorf("%s: %w: нет начальной реплики %q", prefix, errBadDialogue, nd.Start))
+	}
+	for _, dd := range nd.Nodes {
+		for i, cd := range dd.Choices {
+			where := fmt.Sprintf("%s, реплика %q, ответ #%d", prefix, dd.ID, i+1)
+			if cd.Next != "" && !nodes[cd.Next] {
+				errs = append(errs, fmt.Errorf("%s: %w: нет реплики %q", where, errBadDialogue, cd.Next))
+			}
+			if cd.Next == "" && !cd.End {
+				errs = append(errs, fmt.Errorf("%s: %w: ответ никуда не ведёт", where, errBadDialogue))
+			}
+			for _, item := range []string{cd.Give, cd.Take} {
+				if item != "" && !d.hasItem(item) {
+					errs = append(errs, fmt.Errorf("%s: %w: нет предмета %q", where, errBadDialogue, item))
+				}
+			}
+			for _, ref := range []string{cd.Unlock, cd.Lock} {
+				if ref != "" && !d.hasPathRef(ref) {
+					errs = append(errs, fmt.Errorf("%s: %w: нет выхода %q", where, errBadDialogue, ref))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// npc находит персонажа по имени во всём мире
+func (w *World) npc(name string) *NPC {
+	for _, r := range w.rooms {
+		if npc, ok := r.npcs[name]; ok {
+			return npc
+		}
+	}
+	return nil
+}
+
+// npcNames — персонажи комнаты для вывода
+func npcNames(r *Room) string {
+	return strings.Join(sortedKeys(r.npcs), ", ")
+}
+
+// withNPCs дописывает к описанию комнаты, кто в ней находится
+func withNPCs(p *Player, r *Room, answer string) string {
+	if len(r.npcs) == 0 {
+		return answer
+	}
+	return withEvents(answer, []string{p.tr("здесь есть: %s", npcNames(r))})
+}
+
+// --- Команды "поговорить" и "ответить" ---
+
+func (w *World) handleTalk(p *Player, args []string) string {
+	npc, ok := p.room.npcs[args[0]]
+	if !ok {
+		return p.tr("здесь нет - %s", args[0])
+	}
+	p.talking = npc.name
+	return w.showNode(p, npc, p.dialogState(npc))
+}
+
+func (w *World) handleAnswer(p *Player, args []string) string {
+	npc, ok := p.room.npcs[p.talking]
+	if !ok {
+		p.talking = ""
+		return p.tr("вы ни с кем не разговариваете")
+	}
+	node := npc.nodes[p.dialogState(npc)]
+	ev := &Event{Player: p, Room: p.room}
+	visible := []*Choice{}
+	for _, c := range node.choices {
+		if c.visible(ev) {
+			visible = append(visible, c)
+		}
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(visible) {
+		return p.tr("нет такого варианта - %s", args[0])
+	}
+	c := visible[n-1]
+
+	msgs := []string{}
+	if c.take != "" {
+		delete(p.inventory, c.take)
+		msgs = append(msgs, p.tr("вы отдали: %s", c.take))
+	}
+	w.fire(c.effect, ev)
+	if c.effect.say != "" {
+		msgs = append(msgs, npc.name+": "+c.effect.say)
+	}
+	if c.give != "" && !w.itemPlaced(c.give) {
+		msgs = append(msgs, w.give(p, c.give))
+	}
+	if c.next != "" {
+		p.dialogs[npc.name] = c.next
+	}
+	if c.end || c.next == "" {
+		p.talking = ""
+		if len(msgs) == 0 {
+			return p.tr("разговор окончен")
+		}
+		return strings.Join(msgs, ". ")
+	}
+	return strings.Join(append(msgs, w.showNode(p, npc, c.next)), ". ")
+}
+
+// dialogState — реплика, на которой игрок остановился с персонажем
+func (p *Player) dialogState(npc *NPC) string {
+	if node, ok := p.dialogs[npc.name]; ok {
+		return node
+	}
+	return npc.start
+}
+
+// showNode выводит реплику персонажа и доступные игроку ответы
+func (w *World) showNode(p *Player, npc *NPC, id string) string {
+	node := npc.nodes[id]
+	p.dialogs[npc.name] = id
+	ev := &Event{Player: p, Room: p.room}
+	options := []string{}
+	for _, c := range node.choices {
+		if c.visible(ev) {
+			options = append(options, fmt.Sprintf("%d - %s", len(options)+1, c.text))
+		}
+	}
+	answer := npc.name + ": " + node.text
+	if len(options) == 0 {
+		p.talking = ""
+		return answer
+	}
+	return p.tr("%s ответы: %s", sentence(answer), strings.Join(options, ", "))
+}
+
+// sentence ставит точку в конце реплики, если там нет другого знака
+func sentence(s string) string {
+	if strings.HasSuffix(s, ".") || strings.HasSuffix(s, "?") || strings.HasSuffix(s, "!") {
+		return s
+	}
+	return s + "."
+}
+
+// give отдаёт игроку предмет, а если его некуда положить — кладёт рядом
+func (w *World) give(p *Player, name string) string {
+	it := w.items[name]
+	if len(p.inventory) < p.capacity() {
+		p.inventory[name] = it
+		return p.tr("предмет добавлен в инвентарь: %s", name)
+	}
+	p.room.items[name] = it
+	return p.tr("вам некуда положить, %s теперь лежит рядом", name)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const npcTestWorld = `{
+	"start": "двор",
+	"items": [
+		{"name": "сумка", "wearable": true, "capacity": 2},
+		{"name": "бутерброд"},
+		{"name": "фонарь"}
+	],
+	"rooms": [
+		{
+			"name": "двор",
+			"description": "во дворе тихо",
+			"items": ["сумка", "бутерброд"],
+			"npcs": [{
+				"name": "сторож",
+				"description": "старый сторож в ватнике",
+				"start": "привет",
+				"nodes": [
+					{"id": "привет", "text": "чего надо?", "choices": [
+						{"text": "пустите в подвал", "next": "подвал"},
+						{"text": "ничего", "end": true}
+					]},
+					{"id": "подвал", "text": "а что мне за это будет?", "choices": [
+						{"text": "угостить бутербродом", "take": "бутерброд", "say": "другое дело", "unlock": "двор/подвал", "describe": "во дворе тихо, сторож жуёт", "next": "друзья"},
+						{"text": "уйти", "next": "привет", "end": true}
+					]},
+					{"id": "друзья", "text": "заходи, не стесняйся", "choices": [
+						{"text": "а фонарь есть?", "if": {"notHas": "фонарь"}, "give": "фонарь", "say": "держи", "next": "друзья", "end": true}
+					]}
+				]
+			}],
+			"paths": [{"to": "подвал", "locked": true, "lockMsg": "сторож не пускает"}]
+		},
+		{"name": "подвал", "description": "в подвале сыро", "paths": [{"name": "наверх", "to": "двор"}]}
+	]
+}`
+
+func TestNPCDialogue(t *testing.T) {
+	if err := initGameFrom(strings.NewReader(npcTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	cases := []gameCase{
+		{1, "осмотреться", "во дворе тихо, на полу: бутерброд, сумка. можно пройти - подвал. здесь есть: сторож"},
+		{2, "осмотреть сторожа", "старый сторож в ватнике"},
+		{3, "ответить 1", "вы ни с кем не разговариваете"},
+		{4, "поговорить с охранником", "здесь нет - охранником"},
+		{5, "поговорить со сторожем", "сторож: чего надо? ответы: 1 - пустите в подвал, 2 - ничего"},
+		{6, "ответить 3", "нет такого варианта - 3"},
+		{7, "ответить 1", "сторож: а что мне за это будет? ответы: 1 - уйти"}, // угощать нечем
+		{8, "ответить 1", "разговор окончен"},
+		{9, "идти подвал", "сторож не пускает"},
+		{10, "надеть сумка", "вы надели: сумка"},
+		{11, "взять бутерброд", "предмет добавлен в инвентарь: бутерброд"},
+		{12, "поговорить сторож", "сторож: чего надо? ответы: 1 - пустите в подвал, 2 - ничего"},
+		{13, "ответить 1", "сторож: а что мне за это будет? ответы: 1 - угостить бутербродом, 2 - уйти"},
+		{14, "ответить 1", "вы отдали: бутерброд. сторож: другое дело. сторож: заходи, не стесняйся. ответы: 1 - а фонарь есть?"},
+		{15, "ответить 1", "сторож: держи. предмет добавлен в инвентарь: фонарь"},
+		{16, "поговорить сторож", "сторож: заходи, не стесняйся"}, // фонарь уже получен
+		{17, "осмотреться", "во дворе тихо, сторож жуёт. можно пройти - подвал. здесь есть: сторож"},
+		{18, "идти подвал", "в подвале сыро. можно пройти - наверх"},
+		{19, "поговорить сторож", "здесь нет - сторож"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
+
+func TestDialogueStateIsPerPlayer(t *testing.T) {
+	if err := initGameFrom(strings.NewReader(npcTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	handleCommand("поговорить сторож")
+	handleCommand("ответить 1")
+	s, err := world.Join("гость")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if answer := s.Handle("поговорить сторож"); answer != "сторож: чего надо? ответы: 1 - пустите в подвал, 2 - ничего" {
+		t.Errorf("guest: unexpected answer %q", answer)
+	}
+
+	buf := &bytes.Buffer{}
+	if err = world.Save(buf); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err = initGameFrom(strings.NewReader(npcTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err = world.Load(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if answer := handleCommand("поговорить сторож"); answer != "сторож: а что мне за это будет? ответы: 1 - уйти" {
+		t.Errorf("dialogue state was not restored: %q", answer)
+	}
+
+	bad := strings.Replace(buf.String(), `"подвал"`, `"погреб"`, 1)
+	if err = world.Load(strings.NewReader(bad)); !errors.Is(err, errSnapshotMismatch) {
+		t.Errorf("expected errSnapshotMismatch, got %v", err)
+	}
+}
+
+func TestNPCValidation(t *testing.T) {
+	cases := []struct {
+		src  string
+		want error
+	}{
+		{`{"start": "а", "rooms": [{"name": "а", "npcs": [{"name": "б", "start": "x", "nodes": [{"id": "x", "text": "?"}]}, {"name": "б", "start": "x", "nodes": [{"id": "x", "text": "?"}]}]}]}`, errDuplicateNPC},
+		{`{"start": "а", "rooms": [{"name": "а", "npcs": [{"name": "б", "start": "нет", "nodes": [{"id": "x", "text": "?"}]}]}]}`, errBadDialogue},
+		{`{"start": "а", "rooms": [{"name": "а", "npcs": [{"name": "б", "start": "x", "nodes": [{"id": "x", "text": "?", "choices": [{"text": "!"}]}]}]}]}`, errBadDialogue},
+		{`{"start": "а", "rooms": [{"name": "а", "npcs": [{"name": "б", "start": "x", "nodes": [{"id": "x", "text": "?", "choices": [{"text": "!", "give": "нет", "end": true}]}]}]}]}`, errBadDialogue},
+		{`{"start": "а", "rooms": [{"name": "а", "npcs": [{"name": "б", "start": "x", "nodes": [{"id": "x", "text": "?", "choices": [{"text": "!", "unlock": "а/нет", "end": true}]}]}]}]}`, errBadDialogue},
+		{`{"start": "а", "rooms": [{"name": "а", "npcs": [{"start": "x", "nodes": [{"id": "x", "text": "?"}]}]}]}`, errEmptyName},
+	}
+	for i, c := range cases {
+		def, err := parseWorldDef(strings.NewReader(c.src))
+		if err != nil {
+			t.Fatalf("case %d: parse: %v", i+1, err)
+		}
+		if err := def.validate(); !errors.Is(err, c.want) {
+			t.Errorf("case %d: expected %v, got %v", i+1, c.want, err)
+		}
+	}
+}
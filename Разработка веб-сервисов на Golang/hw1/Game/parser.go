@@ -0,0 +1,170 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// --- Разбор команд на естественном языке ---
+//
+// Команда приводится к нижнему регистру, из аргументов выбрасываются
+// предлоги ("иди в коридор" -> "идти коридор"), а словоформы сводятся
+// к именам из мира ("взять ключ" -> "взять ключи", "к двери" -> "дверь").
+// Опечатки в глаголе не исправляются молча, игроку предлагаются варианты.
+
+// prepositions не несут смысла для команд и выбрасываются из аргументов
+var prepositions = map[string]bool{
+	"в": true, "во": true, "на": true, "к": true, "ко": true, "с": true, "со": true, "из": true,
+}
+
+// endings — окончания, которые отрезаются при сравнении словоформ, длинные первыми
+var endings = []string{
+	"ами", "ями", "ого", "его", "ому", "ему",
+	"ой", "ей", "ом", "ем", "ах", "ях", "ов", "ев",
+	"ы", "и", "а", "я", "у", "ю", "е", "о", "ь", "й",
+}
+
+// minStem — короче этого основу не укорачиваем, чтобы "чай" и "чаю" не слиплись с чем попало
+const minStem = 3
+
+// stem грубо отрезает падежное окончание
+func stem(word string) string {
+	for _, end := range endings {
+		if strings.HasSuffix(word, end) && utf8.RuneCountInString(word)-utf8.RuneCountInString(end) >= minStem {
+			return strings.TrimSuffix(word, end)
+		}
+	}
+	return word
+}
+
+// parseArgs выбрасывает предлоги и сводит словоформы к известным игроку именам
+func (w *World) parseArgs(p *Player, args []string) []string {
+	vocab := w.vocabulary(p)
+	parsed := make([]string, 0, len(args))
+	for _, arg := range args {
+		if prepositions[arg] || p.lang().preps[arg] {
+			continue
+		}
+		parsed = append(parsed, normalize(arg, vocab))
+	}
+	return parsed
+}
+
+// normalize возвращает имя из словаря с той же основой, если оно единственное
+func normalize(word string, vocab map[string]bool) string {
+	if vocab[word] {
+		return word
+	}
+	s := stem(word)
+	match := ""
+	for name := range vocab {
+		if stem(name) != s {
+			continue
+		}
+		if match != "" {
+			return word // неоднозначно — оставляем как есть
+		}
+		match = name
+	}
+	if match == "" {
+		return word
+	}
+	return match
+}
+
+// vocabulary — имена, которые игрок может упомянуть здесь: предметы вокруг
+// и у себя, мебель, выходы из комнаты, цели, к которым применимы его предметы,
+// и то, с чем их можно соединить по рецептам
+func (w *World) vocabulary(p *Player) map[string]bool {
+	vocab := make(map[string]bool)
+	for _, items := range []map[string]*Item{p.room.visible(), p.inventory, p.worn} {
+		for name, it := range items {
+			vocab[name] = true
+			if it.lock != nil {
+				vocab[it.lock.name] = true
+			}
+			for _, target := range it.usableOn {
+				vocab[target] = true
+			}
+		}
+	}
+	for _, rc := range w.recipes {
+		if p.inventory[rc.items[0]] != nil || p.inventory[rc.items[1]] != nil {
+			vocab[rc.items[0]], vocab[rc.items[1]] = true, true
+		}
+	}
+	for name := range p.room.npcs {
+		vocab[name] = true
+	}
+	for _, c := range p.room.containers {
+		vocab[c.name] = true
+		if c.lock != nil {
+			vocab[c.lock.name] = true
+		}
+	}
+	for name, path := range p.room.paths {
+		vocab[name] = true
+		if path.lock != nil {
+			vocab[path.lock.name] = true
+		}
+	}
+	return vocab
+}
+
+// suggest подбирает самые близкие по написанию команды, если они достаточно близки
+func (w *World) suggest(p *Player, verb string) []string {
+	best := -1
+	var names []string
+	seen := map[*Command]bool{}
+	for _, cr := range []*commandRegistry{p.room.commands, w.commands} {
+		if cr == nil {
+			continue
+		}
+		for _, c := range cr.order {
+			for _, name := range p.lang().spellings(c) {
+				d := levenshtein(verb, name)
+				if d > maxTypos(name) || (best >= 0 && d > best) {
+					continue
+				}
+				if d < best || best < 0 {
+					best, names, seen = d, nil, map[*Command]bool{}
+				}
+				if !seen[c] {
+					seen[c] = true
+					names = append(names, p.lang().verb(c.Name))
+				}
+			}
+		}
+	}
+	return names
+}
+
+// maxTypos — сколько опечаток допускаем в слове такой длины
+func maxTypos(word string) int {
+	if utf8.RuneCountInString(word) <= 5 {
+		return 1
+	}
+	return 2
+}
+
+// levenshtein — редакционное расстояние между строками по рунам
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNaturalCommands(t *testing.T) {
+	initGame()
+	cases := []gameCase{
+		{1, "Осмотрись", "ты находишься на кухне, на столе: чай, надо собрать рюкзак и идти в универ. можно пройти - коридор"},
+		{2, "иди в коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{3, "пойти в комнату", "ты в своей комнате. можно пройти - коридор"},
+		{4, "надень рюкзака", "вы надели: рюкзак"},
+		{5, "возьми ключ", "предмет добавлен в инвентарь: ключи"},
+		{6, "взять конспект", "предмет добавлен в инвентарь: конспекты"},
+		{7, "идти в коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{8, "применить ключи к двери", "дверь открыта"},
+		{9, "идти на улицу", "на улице весна. можно пройти - домой"},
+		{10, "взять телефон", "нет такого"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
+
+func TestSuggestCommand(t *testing.T) {
+	initGame()
+	cases := map[string]string{
+		"осмотрется": "неизвестная команда, возможно, вы имели в виду: осмотреться",
+		"взят ключи": "неизвестная команда, возможно, вы имели в виду: взять",
+		"инвентрь":   "неизвестная команда, возможно, вы имели в виду: инвентарь",
+		"завтракать": "неизвестная команда",
+	}
+	for cmd, want := range cases {
+		if got := handleCommand(cmd); got != want {
+			t.Errorf("cmd %q: got %q, want %q", cmd, got, want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	vocab := map[string]bool{"ключи": true, "конспекты": true, "дверь": true, "коридор": true}
+	cases := map[string]string{
+		"ключ":      "ключи",
+		"ключей":    "ключи",
+		"двери":     "дверь",
+		"конспекты": "конспекты",
+		"коридору":  "коридор",
+		"шкаф":      "шкаф",
+	}
+	for word, want := range cases {
+		if got := normalize(word, vocab); got != want {
+			t.Errorf("normalize(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"идти", "идти", 0},
+		{"иди", "идти", 1},
+		{"взтяь", "взять", 2},
+		{"", "чай", 3},
+	}
+	for _, c := range cases {
+		if got := levenshtein(c.a, c.b); got != c.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
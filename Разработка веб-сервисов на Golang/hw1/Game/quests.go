@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// --- Задания ---
+
+var errBadQuest = errors.New("некорректное задание")
+
+// Quest — именованная цель из шагов. Прогресс не хранится, а каждый раз
+// вычисляется по состоянию игрока, поэтому выложенный рюкзак снова
+// делает шаг "собрать рюкзак" невыполненным.
+type Quest struct {
+	name    string
+	ordered bool   // шаги засчитываются только по порядку
+	done    string // что показывать в {goal}, когда всё выполнено
+	steps   []*QuestStep
+}
+
+// QuestStep выполнен, когда выполнены все заданные в нём условия
+type QuestStep struct {
+	text  string
+	wear  string // надет предмет
+	take  string // предмет в инвентаре
+	reach string // игрок побывал в комнате
+}
+
+func (s *QuestStep) complete(p *Player) bool {
+	switch {
+	case s.wear != "" && !p.wears(s.wear):
+		return false
+	case s.take != "" && p.inventory[s.take] == nil:
+		return false
+	case s.reach != "" && !p.visited[s.reach]:
+		return false
+	}
+	return true
+}
+
+// progress возвращает отметки о выполнении для каждого шага
+func (q *Quest) progress(p *Player) []bool {
+	marks := make([]bool, len(q.steps))
+	for i, s := range q.steps {
+		marks[i] = s.complete(p)
+		if q.ordered && i > 0 && !marks[i-1] {
+			marks[i] = false
+		}
+	}
+	return marks
+}
+
+// outstanding — тексты невыполненных шагов
+func (q *Quest) outstanding(p *Player) []string {
+	left := []string{}
+	for i, ok := range q.progress(p) {
+		if !ok {
+			left = append(left, q.steps[i].text)
+		}
+	}
+	return left
+}
+
+// goal — текущая цель игрока для подстановки {goal} в описания комнат:
+// невыполненные шаги первого незавершённого задания. Цель — часть текста мира,
+// поэтому связки вокруг шагов не переводятся, иначе в описании смешаются языки
+func (w *World) goal(p *Player) string {
+	for _, q := range w.quests {
+		if left := q.outstanding(p); len(left) > 0 {
+			return "надо " + strings.Join(left, " и ")
+		}
+	}
+	if len(w.quests) == 0 {
+		return ""
+	}
+	return w.quests[len(w.quests)-1].done
+}
+
+func (w *World) handleQuests(p *Player, _ []string) string {
+	if len(w.quests) == 0 {
+		return p.tr("заданий нет")
+	}
+	lines := make([]string, 0, len(w.quests))
+	for _, q := range w.quests {
+		marks := q.progress(p)
+		done := 0
+		steps := make([]string, 0, len(q.steps))
+		for i, ok := range marks {
+			mark := "[ ]"
+			if ok {
+				mark = "[+]"
+				done++
+			}
+			steps = append(steps, mark+" "+q.steps[i].text)
+		}
+		lines = append(lines, fmt.Sprintf("%s (%d/%d): %s", q.name, done, len(marks), strings.Join(steps, ", ")))
+	}
+	return p.tr("задания: %s", strings.Join(lines, "; "))
+}
+
+// QuestDef описывает задание в файле мира
+type QuestDef struct {
+	Name    string         `json:"name"`
+	Ordered bool           `json:"ordered,omitempty"`
+	Done    string         `json:"done,omitempty"`
+	Steps   []QuestStepDef `json:"steps"`
+}
+
+// QuestStepDef описывает шаг задания
+type QuestStepDef struct {
+	Text  string `json:"text"`
+	Wear  string `json:"wear,omitempty"`
+	Take  string `json:"take,omitempty"`
+	Reach string `json:"reach,omitempty"`
+}
+
+func (qd QuestDef) quest() *Quest {
+	q := &Quest{name: qd.Name, ordered: qd.Ordered, done: qd.Done}
+	for _, sd := range qd.Steps {
+		q.steps = append(q.steps, &QuestStep{text: sd.Text, wear: sd.Wear, take: sd.Take, reach: sd.Reach})
+	}
+	return q
+}
+
+func (d *WorldDef) validateQuests(rooms map[string]*RoomDef) []error {
+	var errs []error
+	seen := make(map[string]bool, len(d.Quests))
+	for _, qd := range d.Quests {
+		if qd.Name == "" || seen[qd.Name] {
+			errs = append(errs, fmt.Errorf("%w: пустое или повторное имя %q", errBadQuest, qd.Name))
+		}
+		seen[qd.Name] = true
+		if len(qd.Steps) == 0 {
+			errs = append(errs, fmt.Errorf("задание %q: %w: нет шагов", qd.Name, errBadQuest))
+		}
+		for i, sd := range qd.Steps {
+			prefix := fmt.Sprintf("задание %q, шаг #%d", qd.Name, i+1)
+			if sd.Text == "" {
+				errs = append(errs, fmt.Errorf("%s: %w: нет текста", prefix, errBadQuest))
+			}
+			if sd.Wear == "" && sd.Take == "" && sd.Reach == "" {
+				errs = append(errs, fmt.Errorf("%s: %w: нет условий", prefix, errBadQuest))
+			}
+			for _, item := range []string{sd.Wear, sd.Take} {
+				if item != "" && !d.hasItem(item) {
+					errs = append(errs, fmt.Errorf("%s: %w: нет предмета %q", prefix, errBadQuest, item))
+				}
+			}
+			if _, ok := rooms[sd.Reach]; sd.Reach != "" && !ok {
+				errs = append(errs, fmt.Errorf("%s: %w: нет комнаты %q", prefix, errBadQuest, sd.Reach))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestQuestProgress(t *testing.T) {
+	initGame()
+	cases := []gameCase{
+		{1, "задания", "задания: в универ (0/2): [ ] собрать рюкзак, [ ] идти в универ"},
+		{2, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{3, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{4, "надеть рюкзак", "вы надели: рюкзак"},
+		{5, "задания", "задания: в универ (1/2): [+] собрать рюкзак, [ ] идти в универ"}, // как в исходной игре: достаточно надеть рюкзак
+		{6, "взять конспекты", "предмет добавлен в инвентарь: конспекты"},
+		{7, "взять ключи", "предмет добавлен в инвентарь: ключи"},
+		{8, "задания", "задания: в универ (1/2): [+] собрать рюкзак, [ ] идти в универ"},
+		{9, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{10, "применить ключи дверь", "дверь открыта"},
+		{11, "идти улица", "на улице весна. можно пройти - домой"},
+		{12, "задания", "задания: в универ (2/2): [+] собрать рюкзак, [+] идти в универ"},
+		{13, "идти домой", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{14, "идти кухня", "кухня, ничего интересного. можно пройти - коридор"},
+		{15, "осмотреться", "ты находишься на кухне, на столе: чай, можно отдыхать. можно пройти - коридор"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+
+	// посещённые комнаты сохраняются вместе с игроком
+	buf := &bytes.Buffer{}
+	if err := world.Save(buf); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	initGame()
+	if err := world.Load(buf); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if answer := handleCommand("задания"); answer != "задания: в универ (2/2): [+] собрать рюкзак, [+] идти в универ" {
+		t.Errorf("progress was not restored: %q", answer)
+	}
+}
+
+func TestOrderedQuestAndGoalInDescription(t *testing.T) {
+	src := `{
+		"start": "а",
+		"quests": [{"name": "обход", "ordered": true, "done": "обход завершён", "steps": [
+			{"text": "дойти до б", "reach": "б"},
+			{"text": "вернуться с монетой", "take": "монета", "reach": "а"}
+		]}],
+		"items": [{"name": "пояс", "wearable": true, "capacity": 1}],
+		"rooms": [
+			{"name": "а", "description": "старт, {goal}", "items": ["монета", "пояс"], "paths": [{"to": "б"}]},
+			{"name": "б", "description": "тупик", "paths": [{"to": "а"}]}
+		]
+	}`
+	if err := initGameFrom(strings.NewReader(src)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	cases := []gameCase{
+		{1, "надеть пояс", "вы надели: пояс"},
+		{2, "взять монета", "предмет добавлен в инвентарь: монета"},
+		// второй шаг формально выполнен, но порядок не соблюдён
+		{3, "задания", "задания: обход (0/2): [ ] дойти до б, [ ] вернуться с монетой"},
+		{4, "осмотреться", "старт, надо дойти до б и вернуться с монетой. можно пройти - б"},
+		{5, "идти б", "тупик. можно пройти - а"},
+		{6, "идти а", "старт, обход завершён. можно пройти - б"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
+
+func TestQuestValidation(t *testing.T) {
+	src := `{"start": "а", "quests": [
+		{"name": "x", "steps": [{"text": "найти", "take": "клад"}, {"text": "уйти", "reach": "б"}, {"text": "ничего"}]},
+		{"name": "x", "steps": []}
+	], "rooms": [{"name": "а"}]}`
+	def, err := parseWorldDef(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	_, err = newWorld(def)
+	if !errors.Is(err, errBadQuest) {
+		t.Fatalf("expected errBadQuest, got %v", err)
+	}
+	if n := strings.Count(err.Error(), errBadQuest.Error()); n != 5 {
+		t.Errorf("expected 5 quest errors, got %d: %v", n, err)
+	}
+}
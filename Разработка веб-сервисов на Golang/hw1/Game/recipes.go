@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// --- Рецепты ---
+//
+// Рецепт соединяет два предмета в третий: "применить палку тряпку" -> факел.
+// Первый предмет должен быть в инвентаре, второй — в инвентаре или рядом.
+// Результат попадает в инвентарь, а если класть некуда — на пол.
+// Каждый предмет существует в одном экземпляре, поэтому рецепт не сработает,
+// пока его результат уже где-то есть.
+
+var errBadRecipe = errors.New("некорректный рецепт")
+
+// Recipe — рецепт из описания мира
+type Recipe struct {
+	items   [2]string
+	result  string
+	consume []string // какие из items пропадают
+	room    string   // где можно сделать, пусто — где угодно
+	say     string   // ответ вместо "вы сделали: ..."
+}
+
+// RecipeDef описывает рецепт в файле мира
+type RecipeDef struct {
+	Items   []string `json:"items"` // два предмета, порядок в команде не важен
+	Result  string   `json:"result"`
+	Consume []string `json:"consume,omitempty"`
+	Room    string   `json:"room,omitempty"`
+	Say     string   `json:"say,omitempty"`
+}
+
+func (rd RecipeDef) recipe() *Recipe {
+	return &Recipe{
+		items:   [2]string{rd.Items[0], rd.Items[1]},
+		result:  rd.Result,
+		consume: rd.Consume,
+		room:    rd.Room,
+		say:     rd.Say,
+	}
+}
+
+// validateRecipes проверяет рецепты: предметы и комнаты существуют,
+// результат ещё не лежит в мире и не получается двумя способами
+func (d *WorldDef) validateRecipes(rooms map[string]*RoomDef) []error {
+	var errs []error
+	placed := make(map[string]bool)
+	for _, rd := range d.Rooms {
+		for _, it := range rd.allItems() {
+			placed[it.Name] = true
+		}
+	}
+	results := make(map[string]bool, len(d.Recipes))
+	pairs := make(map[[2]string]bool, len(d.Recipes))
+	for i, rd := range d.Recipes {
+		prefix := fmt.Sprintf("рецепт #%d", i+1)
+		if len(rd.Items) != 2 || rd.Items[0] == rd.Items[1] {
+			errs = append(errs, fmt.Errorf("%s: %w: нужны два разных предмета", prefix, errBadRecipe))
+			continue
+		}
+		pair := [2]string{min(rd.Items[0], rd.Items[1]), max(rd.Items[0], rd.Items[1])}
+		if pairs[pair] {
+			errs = append(errs, fmt.Errorf("%s: %w: %s и %s уже соединяются", prefix, errBadRecipe, pair[0], pair[1]))
+		}
+		pairs[pair] = true
+		for _, item := range []string{rd.Items[0], rd.Items[1], rd.Result} {
+			if item == "" || !d.hasItem(item) {
+				errs = append(errs, fmt.Errorf("%s: %w: нет предмета %q", prefix, errBadRecipe, item))
+			}
+		}
+		switch {
+		case placed[rd.Result]:
+			errs = append(errs, fmt.Errorf("%s: %w: %q уже лежит в мире", prefix, errBadRecipe, rd.Result))
+		case results[rd.Result]:
+			errs = append(errs, fmt.Errorf("%s: %w: %q получается другим рецептом", prefix, errBadRecipe, rd.Result))
+		}
+		results[rd.Result] = true
+		for _, item := range rd.Consume {
+			if !slices.Contains(rd.Items, item) {
+				errs = append(errs, fmt.Errorf("%s: %w: %q не входит в рецепт", prefix, errBadRecipe, item))
+			}
+		}
+		if _, ok := rooms[rd.Room]; rd.Room != "" && !ok {
+			errs = append(errs, fmt.Errorf("%s: %w: нет комнаты %q", prefix, errBadRecipe, rd.Room))
+		}
+	}
+	return errs
+}
+
+// recipe находит рецепт, соединяющий a и b в любом порядке
+func (w *World) recipe(a, b string) *Recipe {
+	for _, rc := range w.recipes {
+		if rc.items == [2]string{a, b} || rc.items == [2]string{b, a} {
+			return rc
+		}
+	}
+	return nil
+}
+
+// craft соединяет предмет из инвентаря с target по рецепту rc
+func (w *World) craft(p *Player, rc *Recipe, target string) string {
+	_, held := p.inventory[target]
+	it, from := p.room.find(target)
+	switch {
+	case !held && it == nil:
+		return p.tr("здесь нет - %s", target)
+	case rc.room != "" && p.room.name != rc.room:
+		return p.tr("здесь не получится")
+	case w.itemPlaced(rc.result):
+		return p.tr("уже есть - %s", rc.result)
+	}
+	for _, name := range rc.consume {
+		if _, ok := p.inventory[name]; ok {
+			delete(p.inventory, name)
+			continue
+		}
+		p.room.remove(name, from)
+	}
+	answer := rc.say
+	if answer == "" {
+		answer = p.tr("вы сделали: %s", rc.result)
+	}
+	return withEvents(answer, []string{w.give(p, rc.result)})
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const recipesTestWorld = `{
+	"start": "сарай",
+	"items": [
+		{"name": "рюкзак", "wearable": true, "capacity": 3},
+		{"name": "факел", "description": "самодельный факел"},
+		{"name": "фонарь", "description": "свеча в банке"}
+	],
+	"recipes": [
+		{"items": ["палка", "тряпка"], "result": "факел", "consume": ["палка", "тряпка"], "say": "вы обмотали палку тряпкой"},
+		{"items": ["спички", "свеча"], "result": "фонарь", "consume": ["свеча"], "room": "сарай"}
+	],
+	"rooms": [
+		{"name": "сарай", "description": "в сарае", "items": ["рюкзак", "палка", "тряпка", "спички"], "paths": [{"to": "пещера"}]},
+		{"name": "пещера", "description": "в пещере", "items": ["свеча"], "paths": [{"to": "сарай"}]}
+	]
+}`
+
+func TestRecipes(t *testing.T) {
+	if err := initGameFrom(strings.NewReader(recipesTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	cases := []gameCase{
+		{1, "надеть рюкзак", "вы надели: рюкзак"},
+		{2, "взять палку", "предмет добавлен в инвентарь: палка"},
+		{3, "применить палку тряпку", "вы обмотали палку тряпкой. предмет добавлен в инвентарь: факел"},
+		{4, "применить палка тряпка", "нет предмета в инвентаре - палка"},
+		{5, "осмотреться", "в сарае, на полу: спички. можно пройти - пещера"}, // тряпка израсходована
+		{6, "взять спички", "предмет добавлен в инвентарь: спички"},
+		{7, "применить спички свечу", "здесь нет - свеча"},
+		{8, "идти пещера", "в пещере. можно пройти - сарай"},
+		{9, "взять свечу", "предмет добавлен в инвентарь: свеча"},
+		{10, "применить спички свечу", "здесь не получится"},
+		{11, "идти сарай", "в сарае. можно пройти - пещера"},
+		{12, "применить свечу спички", "вы сделали: фонарь. предмет добавлен в инвентарь: фонарь"}, // порядок не важен
+		{13, "осмотреть фонарь", "свеча в банке"},
+		{14, "инвентарь", "надето: рюкзак. в инвентаре 3 предмета: спички, факел, фонарь"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+	if errs := world.Lint(); errs != nil {
+		t.Errorf("unexpected lint errors: %v", errs)
+	}
+}
+
+func TestRecipeCraftsOnce(t *testing.T) {
+	src := strings.Replace(recipesTestWorld, `"consume": ["палка", "тряпка"], `, "", 1)
+	if err := initGameFrom(strings.NewReader(src)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	cases := []gameCase{
+		{1, "надеть рюкзак", "вы надели: рюкзак"},
+		{2, "взять палку", "предмет добавлен в инвентарь: палка"},
+		{3, "применить палку тряпку", "вы обмотали палку тряпкой. предмет добавлен в инвентарь: факел"},
+		{4, "применить палку тряпку", "уже есть - факел"},
+		{5, "осмотреться", "в сарае, на полу: спички, тряпка. можно пройти - пещера"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
+
+func TestRecipesValidation(t *testing.T) {
+	cases := []struct {
+		name   string
+		recipe string
+	}{
+		{"one item", `{"items": ["палка"], "result": "факел"}`},
+		{"unknown item", `{"items": ["палка", "верёвка"], "result": "факел"}`},
+		{"result already placed", `{"items": ["палка", "тряпка"], "result": "спички"}`},
+		{"same result twice", `{"items": ["спички", "палка"], "result": "фонарь"}`},
+		{"same pair twice", `{"items": ["тряпка", "палка"], "result": "фонарь"}`},
+		{"foreign consume", `{"items": ["спички", "тряпка"], "result": "фонарь", "consume": ["палка"]}`},
+		{"unknown room", `{"items": ["спички", "тряпка"], "result": "фонарь", "room": "чердак"}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := strings.Replace(recipesTestWorld, `"recipes": [`, `"recipes": [`+tc.recipe+",", 1)
+			def, err := parseWorldDef(strings.NewReader(src))
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if err := def.validate(); !errors.Is(err, errBadRecipe) {
+				t.Errorf("got %v, want errBadRecipe", err)
+			}
+		})
+	}
+}
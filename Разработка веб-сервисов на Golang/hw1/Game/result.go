@@ -0,0 +1,123 @@
+package main
+
+// --- Структурированный ответ ---
+//
+// Result — ответ на команду для фронтендов, которым неудобно разбирать текст:
+// где игрок, куда можно пройти, что лежит рядом и что у него с собой.
+// Текстовый ответ (World.Handle, handleCommand) строится из Result методом Text.
+
+// Откуда взялось событие хода
+const (
+	SourceTrigger  = "trigger"  // реакция триггера на команду, её текст уже вошёл в Message
+	SourceSchedule = "schedule" // событие расписания, в Message его нет
+)
+
+// ResultEvent — событие, случившееся за ход
+type ResultEvent struct {
+	Source string `json:"source"`
+	Text   string `json:"text"`
+}
+
+// Exit — выход из комнаты
+type Exit struct {
+	Name   string `json:"name"`
+	To     string `json:"to"`
+	Locked bool   `json:"locked,omitempty"`
+	Lock   string `json:"lock,omitempty"` // как называется замок: "дверь", "решётка"
+}
+
+// State — то, что игрок видит сейчас
+type State struct {
+	Player    string   `json:"player"`
+	Room      string   `json:"room"`
+	Exits     []Exit   `json:"exits"` // в порядке вывода "можно пройти"
+	Items     []string `json:"items"` // предметы в комнате
+	Inventory []string `json:"inventory"`
+	Worn      []string `json:"worn"`
+}
+
+// Result — ответ на команду и состояние игрока после неё
+type Result struct {
+	Message string        `json:"message"`          // ответ команды
+	Events  []ResultEvent `json:"events,omitempty"` // по порядку: расписание, затем триггеры
+	State   State         `json:"state"`
+}
+
+// Text — ответ в том виде, в каком его видит игрок в telnet
+func (r Result) Text() string {
+	var msgs []string
+	for _, ev := range r.Events {
+		if ev.Source == SourceSchedule {
+			msgs = append(msgs, ev.Text)
+		}
+	}
+	return withEvents(r.Message, msgs)
+}
+
+// Result выполняет команду от имени игрока id.
+// Команды всех игроков выполняются под одной блокировкой мира,
+// поэтому изменения (например, открытая дверь) сразу видны остальным.
+func (w *World) Result(id, command string) Result {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	p, ok := w.players[id]
+	if !ok {
+		return Result{Message: locales[defaultLocale].tr("нет такого игрока - %s", id)}
+	}
+	w.fired = nil
+	res := Result{}
+	for _, msg := range w.advance(p) {
+		res.Events = append(res.Events, ResultEvent{Source: SourceSchedule, Text: msg})
+	}
+	journaled, version := w.journaled(p, command), w.version
+	res.Message = w.dispatch(p, command)
+	res.Events = append(res.Events, w.fired...)
+	w.fired = nil
+	res.State = w.state(p)
+	if journaled || w.version != version {
+		p.journal = append(p.journal, journalEntry{command: command, answer: res.Text()})
+		if len(p.journal) > maxJournal {
+			p.journal = p.journal[len(p.journal)-maxJournal:]
+		}
+	}
+	return res
+}
+
+// state — состояние игрока, вызывается под w.mu
+func (w *World) state(p *Player) State {
+	st := State{
+		Player:    p.id,
+		Room:      p.room.name,
+		Exits:     []Exit{},
+		Items:     sortedKeys(p.room.visible()),
+		Inventory: sortedKeys(p.inventory),
+		Worn:      sortedKeys(p.worn),
+	}
+	for _, name := range p.room.exits {
+		path := p.room.paths[name]
+		ex := Exit{Name: name, To: path.to.name, Locked: path.locked()}
+		if path.lock != nil {
+			ex.Lock = path.lock.name
+		}
+		st.Exits = append(st.Exits, ex)
+	}
+	return st
+}
+
+// State возвращает состояние игрока сессии
+func (s *Session) State() State {
+	w := s.world
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.state(s.player)
+}
+
+// Result выполняет команду от имени игрока сессии и возвращает структурированный ответ
+func (s *Session) Result(command string) Result {
+	return s.world.Result(s.player.id, command)
+}
+
+// handleCommandResult — handleCommand со структурированным ответом
+func handleCommandResult(command string) Result {
+	return world.Result(defaultPlayerID, command)
+}
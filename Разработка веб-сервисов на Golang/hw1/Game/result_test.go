@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const resultTestWorld = `{
+	"start": "холл",
+	"rooms": [
+		{
+			"name": "холл",
+			"description": "в холле",
+			"items": ["зонт"],
+			"triggers": [{"on": "look", "say": "часы тикают"}],
+			"paths": [
+				{"to": "двор"},
+				{"to": "подвал", "lock": {"name": "люк", "locked": true, "code": "1"}}
+			]
+		},
+		{"name": "двор", "description": "во дворе", "paths": [{"to": "холл"}]},
+		{"name": "подвал", "description": "в подвале", "paths": [{"to": "холл"}]}
+	],
+	"schedule": [{"at": 2, "say": "пробило полдень"}]
+}`
+
+func TestResult(t *testing.T) {
+	if err := initGameFrom(strings.NewReader(resultTestWorld)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	res := handleCommandResult("идти двор")
+	want := Result{
+		Message: "во дворе. можно пройти - холл",
+		State: State{
+			Player:    defaultPlayerID,
+			Room:      "двор",
+			Exits:     []Exit{{Name: "холл", To: "холл"}},
+			Items:     []string{},
+			Inventory: []string{},
+			Worn:      []string{},
+		},
+	}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("go: got %+v, want %+v", res, want)
+	}
+
+	res = handleCommandResult("идти холл")
+	wantEvents := []ResultEvent{{Source: SourceSchedule, Text: "пробило полдень"}}
+	if !reflect.DeepEqual(res.Events, wantEvents) {
+		t.Errorf("schedule: got events %+v, want %+v", res.Events, wantEvents)
+	}
+	if got, want := res.Text(), "в холле. можно пройти - двор, подвал. пробило полдень"; got != want {
+		t.Errorf("schedule: got text %q, want %q", got, want)
+	}
+	wantExits := []Exit{{Name: "двор", To: "двор"}, {Name: "подвал", To: "подвал", Locked: true, Lock: "люк"}}
+	if !reflect.DeepEqual(res.State.Exits, wantExits) {
+		t.Errorf("exits: got %+v, want %+v", res.State.Exits, wantExits)
+	}
+	if !reflect.DeepEqual(res.State.Items, []string{"зонт"}) {
+		t.Errorf("items: got %v", res.State.Items)
+	}
+
+	// реакция триггера уже в тексте ответа, в событиях она для фронтендов
+	res = handleCommandResult("осмотреться")
+	wantEvents = []ResultEvent{{Source: SourceTrigger, Text: "часы тикают"}}
+	if !reflect.DeepEqual(res.Events, wantEvents) {
+		t.Errorf("trigger: got events %+v, want %+v", res.Events, wantEvents)
+	}
+	if got, want := res.Text(), res.Message; got != want || !strings.HasSuffix(got, ". часы тикают") {
+		t.Errorf("trigger: got text %q, message %q", got, want)
+	}
+
+	res = handleCommandResult("ввести 1 люк")
+	if res.Message != "открыто" || res.State.Exits[1].Locked {
+		t.Errorf("unlock: got %+v", res)
+	}
+}
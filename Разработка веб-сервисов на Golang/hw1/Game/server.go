@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- TCP-сервер (telnet) ---
+
+const (
+	serverFullMsg = "сервер переполнен, попробуйте позже"
+	idleMsg       = "отключено за бездействие"
+	shutdownMsg   = "сервер остановлен"
+	byeMsg        = "до встречи"
+	quitCommand   = "выход"
+
+	farewellTimeout = time.Second
+)
+
+// Server — построчный фронтенд игры: каждое соединение получает своего игрока,
+// каждая строка уходит в World.Handle, ответ пишется обратно одной строкой.
+// Сообщения от других игроков пишутся отдельными строками, как только приходят.
+type Server struct {
+	World       *World
+	MaxConns    int           // 0 — без ограничения
+	IdleTimeout time.Duration // 0 — без таймаута
+
+	mu     sync.Mutex
+	active int
+	nextID int
+	wg     sync.WaitGroup
+}
+
+// Serve принимает соединения, пока не отменён ctx или не закрыт ln.
+// После отмены ctx дожидается закрытия всех открытых сессий.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	stop := context.AfterFunc(ctx, func() {
+		ln.Close() // nolint:errcheck
+	})
+	defer stop()
+	defer s.wg.Wait()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.ServeConn(ctx, conn)
+		}()
+	}
+}
+
+// ServeConn обслуживает одно соединение до команды "выход", обрыва связи,
+// таймаута бездействия или отмены ctx. Соединение закрывается по возврату.
+func (s *Server) ServeConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close() // nolint:errcheck
+
+	if !s.acquire() {
+		writeLine(conn, serverFullMsg)
+		return
+	}
+	defer s.release()
+
+	sess, err := s.World.Join(s.newPlayerID())
+	if err != nil {
+		writeLine(conn, err.Error())
+		return
+	}
+	defer sess.Leave()
+
+	// отмена ctx будит заблокированное чтение, а зависшей записи даёт
+	// не больше farewellTimeout
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetReadDeadline(time.Now())                       // nolint:errcheck
+		conn.SetWriteDeadline(time.Now().Add(farewellTimeout)) // nolint:errcheck
+	})
+	defer stop()
+
+	out := &syncWriter{w: conn}
+	if !writeLine(out, "добро пожаловать, "+sess.ID()) {
+		return
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go pumpMessages(sess, out, done)
+
+	sc := bufio.NewScanner(conn)
+	for {
+		if s.IdleTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(s.IdleTimeout)) // nolint:errcheck
+			// ctx мог отмениться до установки срока и AfterFunc уже отработал
+			if ctx.Err() != nil {
+				conn.SetReadDeadline(time.Now()) // nolint:errcheck
+			}
+		}
+		if !sc.Scan() {
+			break
+		}
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if line == quitCommand {
+			writeLine(out, byeMsg)
+			return
+		}
+		if !writeLine(out, sess.Handle(line)) {
+			return
+		}
+	}
+
+	// прощальное сообщение не должно держать сессию, если клиент не читает
+	conn.SetWriteDeadline(time.Now().Add(farewellTimeout)) // nolint:errcheck
+	switch err := sc.Err(); {
+	case ctx.Err() != nil:
+		writeLine(out, shutdownMsg)
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		writeLine(out, idleMsg)
+	}
+}
+
+// pumpMessages пишет клиенту сообщения от других игроков, пока не закрыт done
+func pumpMessages(sess *Session, out io.Writer, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case <-sess.Notify():
+			for _, msg := range sess.Messages() {
+				if !writeLine(out, msg) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// syncWriter не даёт ответам и сообщениям перемешаться внутри строки
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (sw *syncWriter) Write(b []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	return sw.w.Write(b)
+}
+
+func (s *Server) acquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.MaxConns > 0 && s.active >= s.MaxConns {
+		return false
+	}
+	s.active++
+	return true
+}
+
+func (s *Server) release() {
+	s.mu.Lock()
+	s.active--
+	s.mu.Unlock()
+}
+
+func (s *Server) newPlayerID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	return fmt.Sprintf("игрок-%d", s.nextID)
+}
+
+// writeLine пишет строку ответа, false — если клиент уже недоступен
+func writeLine(w io.Writer, line string) bool {
+	_, err := io.WriteString(w, line+"\n")
+	return err == nil
+}
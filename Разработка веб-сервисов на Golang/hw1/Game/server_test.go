@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dialTestClient(t *testing.T, addr string) *testClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	return &testClient{conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (c *testClient) readLine(t *testing.T) string {
+	t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second)) // nolint:errcheck
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return strings.TrimSuffix(line, "\n")
+}
+
+func (c *testClient) send(t *testing.T, command string) string {
+	t.Helper()
+	if _, err := c.conn.Write([]byte(command + "\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return c.readLine(t)
+}
+
+func startTestServer(t *testing.T, srv *Server) (string, context.CancelFunc, chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(ctx, ln)
+	}()
+	return ln.Addr().String(), cancel, done
+}
+
+func TestServerPlaysGame(t *testing.T) {
+	initGame()
+	addr, cancel, done := startTestServer(t, &Server{World: world})
+	defer cancel()
+
+	c := dialTestClient(t, addr)
+	defer c.conn.Close()
+	if greeting := c.readLine(t); greeting != "добро пожаловать, игрок-1" {
+		t.Fatalf("unexpected greeting %q", greeting)
+	}
+	for _, item := range game0cases[0] {
+		if answer := c.send(t, item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+	if answer := c.send(t, quitCommand); answer != byeMsg {
+		t.Errorf("unexpected answer to quit: %q", answer)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("serve: %v", err)
+	}
+}
+
+func TestServerMaxConns(t *testing.T) {
+	initGame()
+	addr, cancel, done := startTestServer(t, &Server{World: world, MaxConns: 1})
+
+	first := dialTestClient(t, addr)
+	defer first.conn.Close()
+	first.readLine(t)
+
+	second := dialTestClient(t, addr)
+	defer second.conn.Close()
+	if answer := second.readLine(t); answer != serverFullMsg {
+		t.Errorf("expected %q, got %q", serverFullMsg, answer)
+	}
+
+	// при остановке сервер прощается с оставшимися игроками
+	cancel()
+	if answer := first.readLine(t); answer != shutdownMsg {
+		t.Errorf("expected %q, got %q", shutdownMsg, answer)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("serve: %v", err)
+	}
+}
+
+func TestServerIdleTimeout(t *testing.T) {
+	initGame()
+	srv := &Server{World: world, IdleTimeout: 50 * time.Millisecond}
+	client, server := net.Pipe()
+	defer client.Close()
+	finished := make(chan struct{})
+	go func() {
+		srv.ServeConn(context.Background(), server)
+		close(finished)
+	}()
+
+	c := &testClient{conn: client, r: bufio.NewReader(client)}
+	c.readLine(t)
+	if answer := c.send(t, "осмотреться"); !strings.HasPrefix(answer, "ты находишься на кухне") {
+		t.Errorf("unexpected answer %q", answer)
+	}
+	if answer := c.readLine(t); answer != idleMsg {
+		t.Errorf("expected %q, got %q", idleMsg, answer)
+	}
+	<-finished
+
+	world.mu.Lock()
+	defer world.mu.Unlock()
+	if _, ok := world.players["игрок-1"]; ok {
+		t.Errorf("idle player should have left the world")
+	}
+}
+
+func TestServerShutdownWithStuckClient(t *testing.T) {
+	initGame()
+	srv := &Server{World: world}
+	client, server := net.Pipe()
+	defer client.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan struct{})
+	go func() {
+		srv.ServeConn(ctx, server)
+		close(finished)
+	}()
+
+	// клиент ничего не читает, и приветствие повисает на записи
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case <-finished:
+	case <-time.After(farewellTimeout + time.Second):
+		t.Fatal("shutdown is stuck on a client that does not read")
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// --- Сессии игроков ---
+
+// defaultPlayerID — игрок, от имени которого работает handleCommand
+const defaultPlayerID = "игрок"
+
+var (
+	errEmptyPlayerID = errors.New("пустой идентификатор игрока")
+	errPlayerExists  = errors.New("игрок уже в игре")
+	errBadPlayerID   = errors.New("недопустимый идентификатор игрока")
+)
+
+// Session связывает одного игрока с общим миром
+type Session struct {
+	world  *World
+	player *Player
+}
+
+// addPlayer создаёт игрока в стартовой комнате, вызывается под w.mu
+func (w *World) addPlayer(id string) (*Player, error) {
+	if id == "" {
+		return nil, errEmptyPlayerID
+	}
+	if !validPlayerID(id) {
+		return nil, fmt.Errorf("%w: %q", errBadPlayerID, id)
+	}
+	if _, ok := w.players[id]; ok {
+		return nil, fmt.Errorf("%w: %s", errPlayerExists, id)
+	}
+	p := &Player{
+		id:        id,
+		online:    true,
+		room:      w.start,
+		inventory: make(map[string]*Item),
+		worn:      make(map[string]*Item),
+		visited:   map[string]bool{w.start.name: true},
+		dialogs:   make(map[string]string),
+		outbox:    newOutbox(),
+	}
+	w.players[id] = p
+	w.version++
+	return p, nil
+}
+
+// validPlayerID — id не из одних пробелов и без управляющих символов: он
+// выводится другим игрокам, и перевод строки в нём подделал бы чужие реплики
+func validPlayerID(id string) bool {
+	return strings.TrimSpace(id) != "" && strings.IndexFunc(id, unicode.IsControl) < 0
+}
+
+// Join добавляет в мир нового игрока и открывает для него сессию.
+// Если игрок с таким id восстановлен из сохранения и ещё не подключён,
+// сессия продолжает его игру.
+func (w *World) Join(id string) (*Session, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if p, ok := w.players[id]; ok && !p.online {
+		p.online = true
+		if p.outbox == nil {
+			p.outbox = newOutbox()
+		}
+		return &Session{world: w, player: p}, nil
+	}
+	p, err := w.addPlayer(id)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{world: w, player: p}, nil
+}
+
+// ID возвращает идентификатор игрока сессии
+func (s *Session) ID() string {
+	return s.player.id
+}
+
+// Handle выполняет команду от имени игрока сессии
+func (s *Session) Handle(command string) string {
+	return s.world.Handle(s.player.id, command)
+}
+
+// Leave убирает игрока из мира. Всё, что он нёс, остаётся в комнате,
+// чтобы общие предметы (например, ключи) не пропадали для остальных.
+func (s *Session) Leave() {
+	w := s.world
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	p := s.player
+	if w.players[p.id] != p {
+		return
+	}
+	for name, it := range p.inventory {
+		p.room.items[name] = it
+	}
+	for name, it := range p.worn {
+		p.room.items[name] = it
+	}
+	delete(w.players, p.id)
+	w.version++
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestSharedDoorIsVisibleToEveryone(t *testing.T) {
+	initGame()
+	anna, err := world.Join("анна")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	boris, err := world.Join("борис")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	steps := []struct {
+		s       *Session
+		command string
+		answer  string
+	}{
+		{anna, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{anna, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{anna, "надеть рюкзак", "вы надели: рюкзак"},
+		{anna, "взять ключи", "предмет добавлен в инвентарь: ключи"},
+		{boris, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{boris, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{boris, "осмотреться", "на столе: конспекты. можно пройти - коридор"}, // анна уже всё забрала
+		{boris, "надеть рюкзак", "нет такого"},
+		{boris, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{boris, "идти улица", "дверь закрыта"},
+		{anna, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{anna, "применить ключи дверь", "дверь открыта"},
+		{boris, "идти улица", "на улице весна. можно пройти - домой"}, // дверь общая
+	}
+	for i, step := range steps {
+		if answer := step.s.Handle(step.command); answer != step.answer {
+			t.Errorf("step %d: %s: cmd %q: got %q, want %q", i+1, step.s.ID(), step.command, answer, step.answer)
+		}
+	}
+
+	// игрок по умолчанию живёт в том же мире
+	if answer := handleCommand("осмотреться"); answer != "ты находишься на кухне, на столе: чай, надо собрать рюкзак и идти в универ. можно пройти - коридор" {
+		t.Errorf("default player: unexpected answer %q", answer)
+	}
+}
+
+func TestJoinAndLeave(t *testing.T) {
+	initGame()
+	if _, err := world.Join(defaultPlayerID); !errors.Is(err, errPlayerExists) {
+		t.Fatalf("expected errPlayerExists, got %v", err)
+	}
+	if _, err := world.Join(""); !errors.Is(err, errEmptyPlayerID) {
+		t.Fatalf("expected errEmptyPlayerID, got %v", err)
+	}
+
+	s, err := world.Join("анна")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	s.Handle("идти коридор")
+	s.Handle("идти комната")
+	s.Handle("надеть рюкзак")
+	s.Handle("взять ключи")
+	s.Leave()
+
+	if answer := s.Handle("осмотреться"); answer != "нет такого игрока - анна" {
+		t.Errorf("unexpected answer after leave: %q", answer)
+	}
+	// вещи ушедшего игрока остаются в комнате
+	handleCommand("идти коридор")
+	if answer := handleCommand("идти комната"); answer != "ты в своей комнате. можно пройти - коридор" {
+		t.Fatalf("unexpected answer %q", answer)
+	}
+	if answer := handleCommand("осмотреться"); answer != "на столе: конспекты, на полу: ключи, рюкзак. можно пройти - коридор" {
+		t.Errorf("unexpected answer %q", answer)
+	}
+}
+
+func TestConcurrentSessions(t *testing.T) {
+	initGame()
+	const players = 8
+	wg := &sync.WaitGroup{}
+	for i := 0; i < players; i++ {
+		s, err := world.Join(fmt.Sprintf("игрок-%d", i))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, cmd := range []string{"идти коридор", "идти комната", "надеть рюкзак", "взять ключи", "идти коридор", "применить ключи дверь", "идти улица"} {
+				s.Handle(cmd)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// рюкзак и ключи достались ровно одному игроку
+	wearers, holders := 0, 0
+	for _, p := range world.players {
+		if p.wears("рюкзак") {
+			wearers++
+		}
+		if p.inventory["ключи"] != nil {
+			holders++
+		}
+	}
+	if wearers != 1 || holders != 1 {
+		t.Errorf("expected exactly one backpack and one key holder, got %d and %d", wearers, holders)
+	}
+}
@@ -0,0 +1,468 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// --- Сохранение и загрузка состояния ---
+
+// snapshotVersion — текущая версия формата снимка, увеличивается при несовместимых изменениях.
+// Версия 2: вместо флага hasBackpack хранится список надетых предметов.
+// Версия 3: запертые предметы-контейнеры.
+// Версия 4: мебель в комнатах со своими предметами.
+const snapshotVersion = 4
+
+// defaultSaveName — имя сохранения, если в команде оно не указано
+const defaultSaveName = "сохранение"
+
+var (
+	errSnapshotVersion  = errors.New("неподдерживаемая версия снимка")
+	errSnapshotMismatch = errors.New("снимок не подходит к миру")
+)
+
+var saveNameRe = regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)
+
+// snapshot хранит только изменяемое состояние мира. Комнаты и пути в нём
+// записаны по именам, поэтому ссылки *Room восстанавливаются через w.rooms.
+type snapshot struct {
+	Version     int                    `json:"version"`
+	Rooms       map[string]roomState   `json:"rooms"`
+	Players     map[string]playerState `json:"players"`
+	LockedItems []string               `json:"lockedItems,omitempty"` // предметы с запертым замком
+	Clock       int                    `json:"clock,omitempty"`       // игровой ход
+}
+
+type roomState struct {
+	Description string   `json:"description,omitempty"` // триггеры могут менять описание
+	Items       []string `json:"items"`
+	Locked      []string `json:"locked,omitempty"` // имена запертых выходов
+
+	Containers map[string]containerState `json:"containers,omitempty"` // мебель по имени
+}
+
+type containerState struct {
+	Items  []string `json:"items"`
+	Closed bool     `json:"closed,omitempty"`
+	Locked bool     `json:"locked,omitempty"`
+}
+
+type playerState struct {
+	Room        string            `json:"room"`
+	Inventory   []string          `json:"inventory"`
+	Worn        []string          `json:"worn,omitempty"`
+	Visited     []string          `json:"visited,omitempty"`
+	Locale      string            `json:"locale,omitempty"`
+	Dialogs     map[string]string `json:"dialogs,omitempty"`     // персонаж -> реплика
+	HasBackpack bool              `json:"hasBackpack,omitempty"` // только версия 1
+}
+
+// Save записывает состояние мира и всех игроков
+func (w *World) Save(out io.Writer) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.save(out)
+}
+
+// Load восстанавливает состояние, сохранённое Save, в мир с той же структурой.
+// Снимок сначала проверяется целиком, и только потом применяется.
+func (w *World) Load(in io.Reader) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.load(in)
+}
+
+func (w *World) save(out io.Writer) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(w.snapshot())
+}
+
+// snapshot снимает текущее состояние мира, вызывается под w.mu
+func (w *World) snapshot() *snapshot {
+	snap := &snapshot{
+		Version: snapshotVersion,
+		Clock:   w.clock.Now(),
+		Rooms:   make(map[string]roomState, len(w.rooms)),
+		Players: make(map[string]playerState, len(w.players)),
+	}
+	for name, r := range w.rooms {
+		st := roomState{Description: r.description, Items: sortedKeys(r.items)}
+		for pathName, p := range r.paths {
+			if p.locked() {
+				st.Locked = append(st.Locked, pathName)
+			}
+		}
+		sort.Strings(st.Locked)
+		if len(r.containers) > 0 {
+			st.Containers = make(map[string]containerState, len(r.containers))
+		}
+		for _, c := range r.containers {
+			st.Containers[c.name] = containerState{
+				Items:  sortedKeys(c.items),
+				Closed: c.closed,
+				Locked: c.lock != nil && c.lock.locked,
+			}
+		}
+		snap.Rooms[name] = st
+	}
+	for id, p := range w.players {
+		snap.Players[id] = playerState{
+			Room:      p.room.name,
+			Inventory: sortedKeys(p.inventory),
+			Worn:      sortedKeys(p.worn),
+			Visited:   sortedKeys(p.visited),
+			Locale:    p.lang().name,
+			Dialogs:   maps.Clone(p.dialogs),
+		}
+	}
+
+	for _, name := range sortedKeys(w.items) {
+		if l := w.items[name].lock; l != nil && l.locked {
+			snap.LockedItems = append(snap.LockedItems, name)
+		}
+	}
+
+	return snap
+}
+
+func (w *World) load(in io.Reader) error {
+	snap := snapshot{}
+	if err := json.NewDecoder(in).Decode(&snap); err != nil {
+		return fmt.Errorf("снимок: %w", err)
+	}
+	if snap.Version < 1 || snap.Version > snapshotVersion {
+		return fmt.Errorf("%w: %d", errSnapshotVersion, snap.Version)
+	}
+	// до версии 3 замков на предметах не было, их состояние остаётся как в мире
+	itemLocks := snap.Version >= 3
+	// до версии 4 мебели не было, всё лежало в комнате
+	if snap.Version < 4 {
+		w.migrateContainers(&snap)
+	}
+	migrateSnapshot(&snap)
+	if err := w.checkSnapshot(&snap); err != nil {
+		return err
+	}
+	w.restore(&snap, itemLocks)
+	return nil
+}
+
+// restore применяет проверенный снимок. Всё, чего в снимке нет, остаётся как
+// было: игроки не из снимка, а без itemLocks — и замки предметов.
+func (w *World) restore(snap *snapshot, itemLocks bool) {
+	w.takeFromLatecomers(snap)
+	for name, st := range snap.Rooms {
+		r := w.rooms[name]
+		if st.Description != "" {
+			r.description = st.Description
+		}
+		r.items = w.itemSet(st.Items)
+		for _, p := range r.paths {
+			if p.lock != nil {
+				p.lock.locked = false
+			}
+		}
+		for _, pathName := range st.Locked {
+			r.paths[pathName].setLocked(true)
+		}
+		for _, c := range r.containers {
+			cs := st.Containers[c.name]
+			c.items = w.itemSet(cs.Items)
+			c.closed = cs.Closed
+			if c.lock != nil {
+				c.lock.locked = cs.Locked
+			}
+		}
+	}
+	for id, st := range snap.Players {
+		// подключённые игроки остаются теми же объектами, их сессии не рвутся
+		p, ok := w.players[id]
+		if !ok {
+			p = &Player{id: id}
+			w.players[id] = p
+		}
+		p.room = w.rooms[st.Room]
+		p.inventory = w.itemSet(st.Inventory)
+		p.worn = w.itemSet(st.Worn)
+		p.visited = map[string]bool{st.Room: true}
+		for _, name := range st.Visited {
+			p.visited[name] = true
+		}
+		if st.Locale != "" {
+			p.locale = locales[st.Locale]
+		}
+		p.dialogs = make(map[string]string, len(st.Dialogs))
+		for npc, node := range st.Dialogs {
+			p.dialogs[npc] = node
+		}
+		p.talking = ""
+	}
+	w.clock.Set(snap.Clock)
+	w.lastTick = snap.Clock
+	if itemLocks {
+		for _, it := range w.items {
+			if it.lock != nil {
+				it.lock.locked = false
+			}
+		}
+		for _, name := range snap.LockedItems {
+			w.items[name].lock.locked = true
+		}
+	}
+	w.version++
+}
+
+// takeFromLatecomers забирает у игроков не из снимка предметы, которые снимок
+// раскладывает по местам: иначе один предмет оказался бы в двух местах
+func (w *World) takeFromLatecomers(snap *snapshot) {
+	placed := snap.placedItems()
+	for id, p := range w.players {
+		if _, ok := snap.Players[id]; ok {
+			continue
+		}
+		for _, items := range []map[string]*Item{p.inventory, p.worn} {
+			for name := range items {
+				if placed[name] {
+					delete(items, name)
+				}
+			}
+		}
+	}
+}
+
+// placedItems — все предметы, которые лежат где-то в снимке
+func (snap *snapshot) placedItems() map[string]bool {
+	placed := make(map[string]bool)
+	add := func(names []string) {
+		for _, name := range names {
+			placed[name] = true
+		}
+	}
+	for _, st := range snap.Rooms {
+		add(st.Items)
+		for _, cs := range st.Containers {
+			add(cs.Items)
+		}
+	}
+	for _, st := range snap.Players {
+		add(st.Inventory)
+		add(st.Worn)
+	}
+	return placed
+}
+
+// migrateSnapshot приводит снимок старой версии к текущей
+func migrateSnapshot(snap *snapshot) {
+	if snap.Version == 1 {
+		for id, st := range snap.Players {
+			if st.HasBackpack {
+				st.Worn = append(st.Worn, "рюкзак")
+				st.HasBackpack = false
+				snap.Players[id] = st
+			}
+		}
+	}
+	snap.Version = snapshotVersion
+}
+
+// migrateContainers раскладывает предметы комнат из старого снимка по мебели,
+// где они лежат в начале игры. Открыта ли мебель, берётся из текущего мира.
+func (w *World) migrateContainers(snap *snapshot) {
+	for name, st := range snap.Rooms {
+		r, ok := w.rooms[name]
+		if !ok || len(r.containers) == 0 {
+			continue
+		}
+		st.Containers = make(map[string]containerState, len(r.containers))
+		for _, c := range r.containers {
+			st.Containers[c.name] = containerState{Items: []string{}, Closed: c.closed, Locked: c.lock != nil && c.lock.locked}
+		}
+		floor := []string{}
+		for _, item := range st.Items {
+			c := w.homes[item]
+			if c == nil || r.container(c.name) != c {
+				floor = append(floor, item)
+				continue
+			}
+			cs := st.Containers[c.name]
+			cs.Items = append(cs.Items, item)
+			st.Containers[c.name] = cs
+		}
+		st.Items = floor
+		snap.Rooms[name] = st
+	}
+}
+
+func (w *World) itemSet(names []string) map[string]*Item {
+	items := make(map[string]*Item, len(names))
+	for _, name := range names {
+		items[name] = w.items[name]
+	}
+	return items
+}
+
+// checkSnapshot убеждается, что все комнаты, выходы и предметы снимка есть в мире,
+// а каждый предмет находится ровно в одном месте
+func (w *World) checkSnapshot(snap *snapshot) error {
+	sc := &snapshotCheck{w: w, placed: make(map[string]bool, len(w.items))}
+	if len(snap.Rooms) != len(w.rooms) {
+		return fmt.Errorf("%w: в снимке %d комнат, в мире %d", errSnapshotMismatch, len(snap.Rooms), len(w.rooms))
+	}
+	for name, st := range snap.Rooms {
+		if err := sc.room(name, st); err != nil {
+			return err
+		}
+	}
+	for _, name := range snap.LockedItems {
+		if it, ok := w.items[name]; !ok || it.lock == nil {
+			return fmt.Errorf("%w: у предмета %q нет замка", errSnapshotMismatch, name)
+		}
+	}
+	for id, st := range snap.Players {
+		if err := sc.player(id, st); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotCheck помнит, какие предметы снимка уже встречались
+type snapshotCheck struct {
+	w      *World
+	placed map[string]bool
+}
+
+func (sc *snapshotCheck) items(where string, names []string) error {
+	for _, name := range names {
+		if _, ok := sc.w.items[name]; !ok {
+			return fmt.Errorf("%w: %s: неизвестный предмет %q", errSnapshotMismatch, where, name)
+		}
+		if sc.placed[name] {
+			return fmt.Errorf("%w: %s: предмет %q уже встречался", errSnapshotMismatch, where, name)
+		}
+		sc.placed[name] = true
+	}
+	return nil
+}
+
+func (sc *snapshotCheck) room(name string, st roomState) error {
+	r, ok := sc.w.rooms[name]
+	if !ok {
+		return fmt.Errorf("%w: нет комнаты %q", errSnapshotMismatch, name)
+	}
+	for _, pathName := range st.Locked {
+		if _, ok := r.paths[pathName]; !ok {
+			return fmt.Errorf("%w: в комнате %q нет выхода %q", errSnapshotMismatch, name, pathName)
+		}
+	}
+	if err := sc.items("комната "+name, st.Items); err != nil {
+		return err
+	}
+	for cname, cs := range st.Containers {
+		c := r.container(cname)
+		if c == nil {
+			return fmt.Errorf("%w: в комнате %q нет мебели %q", errSnapshotMismatch, name, cname)
+		}
+		if cs.Locked && c.lock == nil {
+			return fmt.Errorf("%w: у мебели %q нет замка", errSnapshotMismatch, cname)
+		}
+		if err := sc.items("мебель "+cname, cs.Items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sc *snapshotCheck) player(id string, st playerState) error {
+	if !validPlayerID(id) {
+		return fmt.Errorf("%w: %w %q", errSnapshotMismatch, errBadPlayerID, id)
+	}
+	for _, name := range append([]string{st.Room}, st.Visited...) {
+		if _, ok := sc.w.rooms[name]; !ok {
+			return fmt.Errorf("%w: игрок %q: неизвестная комната %q", errSnapshotMismatch, id, name)
+		}
+	}
+	for name, node := range st.Dialogs {
+		if npc := sc.w.npc(name); npc == nil || npc.nodes[node] == nil {
+			return fmt.Errorf("%w: игрок %q: нет реплики %q персонажа %q", errSnapshotMismatch, id, node, name)
+		}
+	}
+	if _, ok := locales[st.Locale]; st.Locale != "" && !ok {
+		return fmt.Errorf("%w: игрок %q: %w %q", errSnapshotMismatch, id, errUnknownLocale, st.Locale)
+	}
+	return sc.items("игрок "+id, append(st.Inventory, st.Worn...))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// --- Команды "сохранить" и "загрузить" ---
+
+// savePath — файл сохранения с расширением ext в каталоге w.saveDir
+func (w *World) savePath(args []string, ext string) (string, bool) {
+	name := defaultSaveName
+	if len(args) > 0 {
+		name = args[0]
+	}
+	if !saveNameRe.MatchString(name) {
+		return name, false
+	}
+	return filepath.Join(w.saveDir, name+ext), true
+}
+
+func (w *World) handleSave(p *Player, args []string) string {
+	path, ok := w.savePath(args, ".json")
+	if !ok {
+		return p.tr("недопустимое имя сохранения - %s", path)
+	}
+	if w.saveDir != "" {
+		if err := os.MkdirAll(w.saveDir, 0o755); err != nil {
+			return p.tr("не удалось сохранить: %s", err)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return p.tr("не удалось сохранить: %s", err)
+	}
+	err = w.save(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return p.tr("не удалось сохранить: %s", err)
+	}
+	return p.tr("игра сохранена")
+}
+
+func (w *World) handleLoad(p *Player, args []string) string {
+	path, ok := w.savePath(args, ".json")
+	if !ok {
+		return p.tr("недопустимое имя сохранения - %s", path)
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p.tr("нет такого сохранения")
+	}
+	if err != nil {
+		return p.tr("не удалось загрузить: %s", err)
+	}
+	defer f.Close() // nolint:errcheck
+	if err = w.load(f); err != nil {
+		return p.tr("не удалось загрузить: %s", err)
+	}
+	return p.tr("игра загружена")
+}
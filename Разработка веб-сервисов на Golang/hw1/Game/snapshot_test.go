@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	initGame()
+	for _, cmd := range []string{"идти коридор", "идти комната", "надеть рюкзак", "взять ключи", "идти коридор", "применить ключи дверь"} {
+		handleCommand(cmd)
+	}
+	buf := &bytes.Buffer{}
+	if err := world.Save(buf); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	saved := buf.String()
+
+	initGame()
+	if err := world.Load(strings.NewReader(saved)); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	// после загрузки ссылки на комнаты снова указывают в новый мир
+	if world.players[defaultPlayerID].room != world.rooms["коридор"] {
+		t.Errorf("player room was not restored")
+	}
+	cases := []gameCase{
+		{1, "идти улица", "на улице весна. можно пройти - домой"}, // дверь осталась открытой
+		{2, "идти домой", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{3, "идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{4, "осмотреться", "на столе: конспекты. можно пройти - коридор"},
+		{5, "взять конспекты", "предмет добавлен в инвентарь: конспекты"}, // рюкзак всё ещё надет
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+
+	// повторное сохранение того же состояния даёт тот же снимок
+	initGame()
+	if err := world.Load(strings.NewReader(saved)); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	again := &bytes.Buffer{}
+	if err := world.Save(again); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if again.String() != saved {
+		t.Errorf("snapshot is not stable:\n%s\nvs\n%s", saved, again.String())
+	}
+}
+
+func TestLoadRejectsBadSnapshots(t *testing.T) {
+	initGame()
+	cases := []struct {
+		src  string
+		want error
+	}{
+		{`{"version": 99, "rooms": {}, "players": {}}`, errSnapshotVersion},
+		{`{"version": 1, "rooms": {"подвал": {"items": []}}, "players": {}}`, errSnapshotMismatch},
+	}
+	for _, c := range cases {
+		if err := world.Load(strings.NewReader(c.src)); !errors.Is(err, c.want) {
+			t.Errorf("expected %v, got %v", c.want, err)
+		}
+	}
+	// неудачная загрузка не меняет мир
+	if answer := handleCommand("осмотреться"); !strings.HasPrefix(answer, "ты находишься на кухне, на столе: чай") {
+		t.Errorf("world changed after failed load: %q", answer)
+	}
+}
+
+func TestSaveLoadCommands(t *testing.T) {
+	initGame()
+	world.saveDir = t.TempDir()
+	cases := []gameCase{
+		{1, "загрузить", "нет такого сохранения"},
+		{2, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{3, "сохранить коридор", "игра сохранена"},
+		{4, "идти кухня", "кухня, ничего интересного. можно пройти - коридор"},
+		{5, "загрузить коридор", "игра загружена"},
+		{6, "идти улица", "дверь закрыта"},
+		{7, "сохранить ../../etc", "недопустимое имя сохранения - ../../etc"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
+
+func TestJoinResumesLoadedPlayer(t *testing.T) {
+	initGame()
+	s, err := world.Join("анна")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	s.Handle("идти коридор")
+	buf := &bytes.Buffer{}
+	if err := world.Save(buf); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	initGame()
+	if err := world.Load(buf); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	s, err = world.Join("анна")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if answer := s.Handle("идти улица"); answer != "дверь закрыта" {
+		t.Errorf("loaded player should be in the corridor, got %q", answer)
+	}
+	if _, err := world.Join("анна"); !errors.Is(err, errPlayerExists) {
+		t.Errorf("expected errPlayerExists, got %v", err)
+	}
+}
+
+func TestSaveCommandsAreLocal(t *testing.T) {
+	initGame()
+	world.saveDir = t.TempDir()
+	s, err := world.Join("анна")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	for _, cmd := range []string{"сохранить", "загрузить", "история"} {
+		if answer := s.Handle(cmd); answer != "команда доступна только в локальной игре" {
+			t.Errorf("cmd %q: got %q", cmd, answer)
+		}
+	}
+	if help := s.Handle("помощь"); strings.Contains(help, "сохранить") {
+		t.Errorf("save listed in help: %q", help)
+	}
+	if answer := handleCommand("сохранить"); answer != "игра сохранена" {
+		t.Errorf("default player: got %q", answer)
+	}
+
+	world.remoteSaves = true
+	if answer := s.Handle("сохранить"); answer != "игра сохранена" {
+		t.Errorf("remote saves: got %q", answer)
+	}
+}
+
+func TestLoadTakesItemsFromLatecomers(t *testing.T) {
+	initGame()
+	world.saveDir = t.TempDir()
+	handleCommand("сохранить")
+	bob, err := world.Join("боб")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	local := &Session{world: world, player: world.players[defaultPlayerID]}
+
+	// боб пришёл после сохранения и взял то, что снимок кладёт в комнату
+	cases := []struct {
+		gameCase
+		sess *Session
+	}{
+		{gameCase{1, "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"}, bob},
+		{gameCase{2, "идти комната", "ты в своей комнате. можно пройти - коридор"}, bob},
+		{gameCase{3, "надеть рюкзак", "вы надели: рюкзак"}, bob},
+		{gameCase{4, "взять ключи", "предмет добавлен в инвентарь: ключи"}, bob},
+		{gameCase{5, "загрузить", "игра загружена"}, local},
+		{gameCase{6, "инвентарь", "у вас ничего нет"}, bob},
+		{gameCase{7, "осмотреться", "на столе: ключи, конспекты, на стуле: рюкзак. можно пройти - коридор"}, bob},
+	}
+	for _, item := range cases {
+		if answer := item.sess.Handle(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
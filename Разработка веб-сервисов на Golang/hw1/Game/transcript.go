@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// --- Сценарии (транскрипты) ---
+//
+// Транскрипт — текстовый файл из пар "команда / ожидаемый ответ":
+//
+//	# комментарий
+//	> осмотреться
+//	ты находишься на кухне, ...
+//	> идти коридор
+//	ничего интересного. ...
+//	---
+//	> завтракать
+//	неизвестная команда
+//
+// Строка "---" начинает новый сценарий на свежем мире.
+
+const (
+	transcriptCommand  = "> "
+	transcriptSplit    = "---"
+	transcriptComment  = "#"
+	transcriptNoAnswer = "(пусто)" // так записывается пустой ответ
+)
+
+var (
+	errTranscriptSyntax = errors.New("ошибка в сценарии")
+	errTranscriptFailed = errors.New("сценарий не совпал")
+)
+
+type transcriptStep struct {
+	line    int
+	command string
+	answer  string
+}
+
+// parseTranscript разбирает транскрипт на сценарии
+func parseTranscript(r io.Reader) ([][]transcriptStep, error) {
+	scenarios := [][]transcriptStep{nil}
+	var step *transcriptStep
+	answer := []string{}
+	flush := func() {
+		// пустые строки между шагами разделяют их, в ответ они не входят
+		for len(answer) > 0 && answer[len(answer)-1] == "" {
+			answer = answer[:len(answer)-1]
+		}
+		if step != nil {
+			step.answer = strings.Join(answer, "\n")
+			if step.answer == transcriptNoAnswer {
+				step.answer = ""
+			}
+			last := len(scenarios) - 1
+			scenarios[last] = append(scenarios[last], *step)
+		}
+		step, answer = nil, answer[:0]
+	}
+
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		switch {
+		case strings.HasPrefix(line, transcriptComment):
+		case line == transcriptSplit:
+			flush()
+			scenarios = append(scenarios, nil)
+		case strings.HasPrefix(line, transcriptCommand):
+			flush()
+			step = &transcriptStep{line: n, command: strings.TrimPrefix(line, transcriptCommand)}
+		case line == "" && step == nil:
+		case step == nil:
+			return nil, fmt.Errorf("%w: строка %d: ответ без команды", errTranscriptSyntax, n)
+		default:
+			answer = append(answer, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	// пустые сценарии (например, после завершающего "---") не нужны
+	result := scenarios[:0]
+	for _, s := range scenarios {
+		if len(s) > 0 {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+// replayTranscript прогоняет каждый сценарий на новом мире из newGame
+// и пишет расхождения в out. Возвращает число несовпавших шагов.
+func replayTranscript(r io.Reader, newGame func() (*World, error), out io.Writer) (int, error) {
+	scenarios, err := parseTranscript(r)
+	if err != nil {
+		return 0, err
+	}
+	failed, total := 0, 0
+	for _, steps := range scenarios {
+		w, err := newGame()
+		if err != nil {
+			return failed, err
+		}
+		for _, st := range steps {
+			total++
+			got := w.Handle(defaultPlayerID, st.command)
+			if got == st.answer {
+				continue
+			}
+			failed++
+			fmt.Fprintf(out, "строка %d: %s%s\n\tожидалось: %s\n\tполучено:  %s\n", st.line, transcriptCommand, st.command, st.answer, got)
+		}
+	}
+	fmt.Fprintf(out, "шагов: %d, расхождений: %d\n", total, failed)
+	return failed, nil
+}
+
+// recordTranscript ведёт интерактивную игру: команды читаются из in,
+// ответы пишутся в out, а вся сессия — в транскрипт tr
+func recordTranscript(w *World, in io.Reader, out, tr io.Writer) error {
+	sc := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, transcriptCommand)
+		if !sc.Scan() {
+			fmt.Fprintln(out)
+			break
+		}
+		cmd := strings.TrimSpace(sc.Text())
+		if cmd == "" {
+			continue
+		}
+		if cmd == quitCommand {
+			break
+		}
+		answer := w.Handle(defaultPlayerID, cmd)
+		fmt.Fprintln(out, answer)
+		if err := writeStep(tr, cmd, answer); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// writeStep дописывает в транскрипт команду и ответ на неё
+func writeStep(tr io.Writer, cmd, answer string) error {
+	if answer == "" {
+		answer = transcriptNoAnswer
+	}
+	_, err := fmt.Fprintf(tr, "%s%s\n%s\n", transcriptCommand, cmd, answer)
+	return err
+}
+
+func runReplay(path, worldFile string, out io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint:errcheck
+	failed, err := replayTranscript(f, func() (*World, error) { return openWorld(worldFile) }, out)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%w: %s", errTranscriptFailed, path)
+	}
+	return nil
+}
+
+func runRecord(path, worldFile string, in io.Reader, out io.Writer) error {
+	w, err := openWorld(worldFile)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = recordTranscript(w, in, out, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
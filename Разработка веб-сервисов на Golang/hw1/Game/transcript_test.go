@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func defaultGame() (*World, error) {
+	return openWorld("")
+}
+
+func TestReplayGame0Transcript(t *testing.T) {
+	f, err := os.Open("testdata/game0.txt")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+	out := &bytes.Buffer{}
+	failed, err := replayTranscript(f, defaultGame, out)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if failed != 0 {
+		t.Errorf("transcript does not match:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "шагов: 35, расхождений: 0") {
+		t.Errorf("unexpected summary: %s", out.String())
+	}
+}
+
+func TestReplayReportsDiff(t *testing.T) {
+	src := `# первый сценарий
+> идти коридор
+ничего интересного. можно пройти - кухня, комната, улица
+> идти улица
+дверь открыта
+---
+> идти улица
+нет пути в улица
+`
+	out := &bytes.Buffer{}
+	failed, err := replayTranscript(strings.NewReader(src), defaultGame, out)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if failed != 1 {
+		t.Fatalf("expected 1 failure, got %d:\n%s", failed, out.String())
+	}
+	want := "строка 4: > идти улица\n\tожидалось: дверь открыта\n\tполучено:  дверь закрыта\n"
+	if !strings.HasPrefix(out.String(), want) {
+		t.Errorf("unexpected report:\n%s", out.String())
+	}
+}
+
+func TestParseTranscriptErrors(t *testing.T) {
+	if _, err := parseTranscript(strings.NewReader("ответ без команды\n")); !errors.Is(err, errTranscriptSyntax) {
+		t.Errorf("expected errTranscriptSyntax, got %v", err)
+	}
+}
+
+func TestParseTranscriptBlankLines(t *testing.T) {
+	src := "> идти коридор\nничего интересного. можно пройти - кухня, комната, улица\n\n> идти улица\nдверь закрыта\n\n"
+	out := &bytes.Buffer{}
+	failed, err := replayTranscript(strings.NewReader(src), defaultGame, out)
+	if err != nil || failed != 0 {
+		t.Errorf("replay: failed %d, err %v:\n%s", failed, err, out)
+	}
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	w, err := defaultGame()
+	if err != nil {
+		t.Fatalf("world: %v", err)
+	}
+	in := strings.NewReader("осмотреться\n\nидти коридор\nзавтракать\nвыход\nидти кухня\n")
+	tr := &bytes.Buffer{}
+	if err := recordTranscript(w, in, &bytes.Buffer{}, tr); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if n := strings.Count(tr.String(), "\n> "); n != 2 {
+		t.Errorf("expected 3 recorded commands, got:\n%s", tr.String())
+	}
+
+	out := &bytes.Buffer{}
+	failed, err := replayTranscript(bytes.NewReader(tr.Bytes()), defaultGame, out)
+	if err != nil || failed != 0 {
+		t.Errorf("recorded transcript does not replay: %v\n%s", err, out.String())
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// --- Декларативное описание мира ---
+
+// defaultWorldJSON — мир из задания, с ним работает initGame
+//
+//go:embed world.json
+var defaultWorldJSON []byte
+
+// Ошибки валидации описания мира (проверяются через errors.Is)
+var (
+	errNoStart         = errors.New("не задана стартовая комната")
+	errUnknownStart    = errors.New("стартовая комната не найдена")
+	errEmptyName       = errors.New("пустое имя")
+	errDuplicateRoom   = errors.New("комната объявлена повторно")
+	errDuplicatePath   = errors.New("путь объявлен повторно")
+	errDanglingPath    = errors.New("путь ведёт в несуществующую комнату")
+	errUnreachableRoom = errors.New("комната недостижима из стартовой")
+	errUnknownHook     = errors.New("неизвестный хук")
+)
+
+// WorldDef описывает игровой мир: комнаты, предметы, пути и стартовую комнату
+type WorldDef struct {
+	Start     string        `json:"start"`
+	ExitOrder string        `json:"exitOrder,omitempty"` // порядок выходов по умолчанию, см. ExitOrderDeclared
+	Items     []ItemDef     `json:"items,omitempty"`     // каталог предметов, на которые комнаты ссылаются по имени
+	Locks     []LockDef     `json:"locks,omitempty"`     // общие замки, на которые выходы и предметы ссылаются по id
+	Quests    []QuestDef    `json:"quests,omitempty"`
+	Recipes   []RecipeDef   `json:"recipes,omitempty"`  // что из чего можно сделать
+	Clock     *ClockDef     `json:"clock,omitempty"`    // игровые сутки
+	Schedule  []ScheduleDef `json:"schedule,omitempty"` // события по времени
+	Rooms     []RoomDef     `json:"rooms"`
+}
+
+// RoomDef описывает одну комнату мира
+type RoomDef struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Look        string            `json:"look,omitempty"`       // шаблон для "осмотреться" с {items}, {contents}, {exits}, {goal}, {time}
+	LookEmpty   string            `json:"lookEmpty,omitempty"`  // шаблон, когда в комнате не видно предметов
+	ByTime      map[string]string `json:"byTime,omitempty"`     // описание в разное время суток
+	Hook        string            `json:"hook,omitempty"`       // имя набора хуков из roomHooks
+	ExitOrder   string            `json:"exitOrder,omitempty"`  // порядок выходов этой комнаты вместо порядка мира
+	Items       []ItemDef         `json:"items,omitempty"`      // предметы на полу
+	Containers  []ContainerDef    `json:"containers,omitempty"` // мебель
+	At          *[2]int           `json:"at,omitempty"`         // клетка на карте [x, y], y растёт к югу
+	NPCs        []NPCDef          `json:"npcs,omitempty"`
+	Paths       []PathDef         `json:"paths,omitempty"`
+	Triggers    []TriggerDef      `json:"triggers,omitempty"`
+}
+
+// PathDef описывает выход из комнаты
+type PathDef struct {
+	Name     string       `json:"name,omitempty"` // как выход называется в команде "идти", по умолчанию To
+	To       string       `json:"to"`
+	Lock     *LockDef     `json:"lock,omitempty"`
+	OneWay   bool         `json:"oneWay,omitempty"` // замок с этой стороны не открыть и не запереть
+	Dir      string       `json:"dir,omitempty"`    // куда ведёт на карте: "север", "юго-запад"...
+	Triggers []TriggerDef `json:"triggers,omitempty"`
+
+	// Замок с одним ключом в старом формате, то же, что lock с именем "дверь"
+	Locked     bool   `json:"locked,omitempty"`
+	UnlockItem string `json:"unlockItem,omitempty"`
+	LockMsg    string `json:"lockMsg,omitempty"`
+	UnlockMsg  string `json:"unlockMsg,omitempty"`
+}
+
+func (p PathDef) name() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.To
+}
+
+// parseWorldDef читает описание мира в формате JSON
+func parseWorldDef(r io.Reader) (*WorldDef, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	def := &WorldDef{}
+	if err := dec.Decode(def); err != nil {
+		return nil, fmt.Errorf("описание мира: %w", err)
+	}
+	return def, nil
+}
+
+// validate проверяет целостность описания и возвращает все найденные ошибки разом
+func (d *WorldDef) validate() error {
+	var errs []error
+	rooms := make(map[string]*RoomDef, len(d.Rooms))
+	for i := range d.Rooms {
+		rd := &d.Rooms[i]
+		if rd.Name == "" {
+			errs = append(errs, fmt.Errorf("комната #%d: %w", i+1, errEmptyName))
+			continue
+		}
+		if _, dup := rooms[rd.Name]; dup {
+			errs = append(errs, fmt.Errorf("комната %q: %w", rd.Name, errDuplicateRoom))
+			continue
+		}
+		rooms[rd.Name] = rd
+	}
+
+	for _, rd := range rooms {
+		if _, ok := roomHooks[rd.Hook]; rd.Hook != "" && !ok {
+			errs = append(errs, fmt.Errorf("комната %q: %w %q", rd.Name, errUnknownHook, rd.Hook))
+		}
+		errs = append(errs, validateTriggers(fmt.Sprintf("комната %q", rd.Name), rd.Triggers, d)...)
+		seen := make(map[string]bool, len(rd.Paths))
+		for _, pd := range rd.Paths {
+			name := pd.name()
+			if name == "" {
+				errs = append(errs, fmt.Errorf("комната %q: путь без цели: %w", rd.Name, errEmptyName))
+				continue
+			}
+			if seen[name] {
+				errs = append(errs, fmt.Errorf("комната %q, путь %q: %w", rd.Name, name, errDuplicatePath))
+			}
+			seen[name] = true
+			if _, ok := rooms[pd.To]; !ok {
+				errs = append(errs, fmt.Errorf("комната %q, путь %q: %w %q", rd.Name, name, errDanglingPath, pd.To))
+			}
+			errs = append(errs, validateTriggers(fmt.Sprintf("комната %q, путь %q", rd.Name, name), pd.Triggers, d)...)
+		}
+	}
+
+	errs = append(errs, d.validateExitOrders()...)
+	errs = append(errs, d.validateItems()...)
+	errs = append(errs, d.validateContainers()...)
+	errs = append(errs, d.validateLocks()...)
+	errs = append(errs, d.validateNPCs()...)
+	errs = append(errs, d.validateClock(rooms)...)
+	errs = append(errs, d.validateQuests(rooms)...)
+	errs = append(errs, d.validateRecipes(rooms)...)
+	errs = append(errs, d.validateLayout()...)
+
+	switch start, ok := rooms[d.Start]; {
+	case d.Start == "":
+		errs = append(errs, errNoStart)
+	case !ok:
+		errs = append(errs, fmt.Errorf("%w: %q", errUnknownStart, d.Start))
+	default:
+		// обход в ширину по всем путям, запертые двери тоже считаются проходимыми
+		reached := map[string]bool{start.Name: true}
+		queue := []*RoomDef{start}
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			for _, pd := range cur.Paths {
+				if next, ok := rooms[pd.To]; ok && !reached[pd.To] {
+					reached[pd.To] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+		for _, rd := range d.Rooms {
+			if rd.Name != "" && !reached[rd.Name] {
+				errs = append(errs, fmt.Errorf("комната %q: %w %q", rd.Name, errUnreachableRoom, d.Start))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// newWorld проверяет описание и строит по нему мир с игроком по умолчанию в стартовой комнате
+func newWorld(d *WorldDef) (*World, error) {
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+
+	w := &World{
+		rooms:    make(map[string]*Room, len(d.Rooms)),
+		players:  make(map[string]*Player),
+		commands: defaultCommands(),
+		locks:    make(map[string]*Lock, len(d.Locks)),
+		homes:    make(map[string]*Container),
+	}
+	for i := range d.Locks {
+		w.locks[d.Locks[i].ID] = d.Locks[i].lock()
+	}
+	w.buildItems(d)
+	for _, rd := range d.Rooms {
+		r := &Room{
+			name:        rd.Name,
+			description: rd.Description,
+			look:        rd.Look,
+			lookEmpty:   rd.LookEmpty,
+			items:       make(map[string]*Item, len(rd.Items)),
+			paths:       make(map[string]*Path, len(rd.Paths)),
+			npcs:        make(map[string]*NPC, len(rd.NPCs)),
+			byTime:      rd.ByTime,
+			triggers:    buildTriggers(rd.Triggers),
+		}
+		for _, nd := range rd.NPCs {
+			r.npcs[nd.Name] = nd.npc()
+		}
+		for _, it := range rd.Items {
+			r.items[it.Name] = w.items[it.Name]
+		}
+		for _, cd := range rd.Containers {
+			c := &Container{
+				name:        cd.Name,
+				description: cd.Description,
+				where:       cd.Where,
+				closable:    cd.Closable,
+				closed:      cd.Closed,
+				lock:        w.buildLock(cd.Lock),
+				items:       make(map[string]*Item, len(cd.Items)),
+			}
+			for _, it := range cd.Items {
+				c.items[it.Name] = w.items[it.Name]
+				w.homes[it.Name] = c
+			}
+			r.containers = append(r.containers, c)
+		}
+		if h, ok := roomHooks[rd.Hook]; ok {
+			r.lookFunc = h.look
+			for _, c := range h.commands {
+				if err := r.RegisterCommand(c); err != nil {
+					return nil, fmt.Errorf("комната %q: %w", r.name, err)
+				}
+			}
+		}
+		w.rooms[r.name] = r
+	}
+	for _, rd := range d.Rooms {
+		r := w.rooms[rd.Name]
+		r.exits = orderExits(rd.Paths, rd.exitOrder(d.ExitOrder))
+		for _, pd := range rd.Paths {
+			r.paths[pd.name()] = &Path{
+				to:       w.rooms[pd.To],
+				lock:     w.buildLock(pd.lockDef()),
+				oneWay:   pd.OneWay,
+				triggers: buildTriggers(pd.Triggers),
+			}
+		}
+	}
+
+	for _, qd := range d.Quests {
+		w.quests = append(w.quests, qd.quest())
+	}
+	for _, rd := range d.Recipes {
+		w.recipes = append(w.recipes, rd.recipe())
+	}
+	w.buildClock(d)
+
+	w.start = w.rooms[d.Start]
+	w.layout(d)
+	if _, err := w.addPlayer(defaultPlayerID); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// readWorld читает описание из r и строит по нему мир
+func readWorld(r io.Reader) (*World, error) {
+	def, err := parseWorldDef(r)
+	if err != nil {
+		return nil, err
+	}
+	return newWorld(def)
+}
+
+// openWorld строит мир из файла path, а при пустом path — мир из задания
+func openWorld(path string) (*World, error) {
+	if path == "" {
+		return readWorld(bytes.NewReader(defaultWorldJSON))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint:errcheck
+	return readWorld(f)
+}
+
+// initGameFrom заменяет текущий мир миром из описания r
+func initGameFrom(r io.Reader) error {
+	w, err := readWorld(r)
+	if err != nil {
+		return err
+	}
+	world = w
+	return nil
+}
+
+// initGame делает новый мир из задания и нового игрока
+func initGame() {
+	w, err := openWorld("")
+	if err != nil {
+		panic("встроенный мир некорректен: " + err.Error())
+	}
+	world = w
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDefaultWorldIsValid(t *testing.T) {
+	def, err := parseWorldDef(strings.NewReader(string(defaultWorldJSON)))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err := def.validate(); err != nil {
+		t.Fatalf("default world is invalid: %v", err)
+	}
+}
+
+func TestInitGameFromCustomWorld(t *testing.T) {
+	src := `{
+		"start": "подвал",
+		"rooms": [
+			{"name": "подвал", "description": "темно", "items": ["фонарь"], "paths": [{"to": "чердак", "locked": true, "unlockItem": "фонарь", "lockMsg": "люк закрыт"}]},
+			{"name": "чердак", "description": "пыльно", "paths": [{"name": "вниз", "to": "подвал"}]}
+		]
+	}`
+	if err := initGameFrom(strings.NewReader(src)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer initGame()
+
+	cases := []gameCase{
+		{1, "осмотреться", "темно, на полу: фонарь. можно пройти - чердак"},
+		{2, "идти чердак", "люк закрыт"},
+	}
+	for _, item := range cases {
+		if answer := handleCommand(item.command); answer != item.answer {
+			t.Errorf("step %d: cmd %q: got %q, want %q", item.step, item.command, answer, item.answer)
+		}
+	}
+}
+
+func TestWorldDefValidation(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		want []error
+	}{
+		{
+			name: "dangling path",
+			src:  `{"start": "а", "rooms": [{"name": "а", "paths": [{"to": "б"}]}]}`,
+			want: []error{errDanglingPath},
+		},
+		{
+			name: "duplicate room",
+			src:  `{"start": "а", "rooms": [{"name": "а"}, {"name": "а"}]}`,
+			want: []error{errDuplicateRoom},
+		},
+		{
+			name: "unreachable room",
+			src:  `{"start": "а", "rooms": [{"name": "а", "paths": [{"to": "б"}]}, {"name": "б"}, {"name": "в", "paths": [{"to": "а"}]}]}`,
+			want: []error{errUnreachableRoom},
+		},
+		{
+			name: "unknown start",
+			src:  `{"start": "я", "rooms": [{"name": "а"}]}`,
+			want: []error{errUnknownStart},
+		},
+		{
+			name: "several problems at once",
+			src:  `{"rooms": [{"name": "а", "hook": "нет", "paths": [{"to": "а"}, {"to": "а"}]}]}`,
+			want: []error{errNoStart, errUnknownHook, errDuplicatePath},
+		},
+	}
+	for _, c := range cases {
+		def, err := parseWorldDef(strings.NewReader(c.src))
+		if err != nil {
+			t.Fatalf("%s: parse: %v", c.name, err)
+		}
+		_, err = newWorld(def)
+		if err == nil {
+			t.Errorf("%s: expected error, got nil", c.name)
+			continue
+		}
+		for _, want := range c.want {
+			if !errors.Is(err, want) {
+				t.Errorf("%s: expected %v in %v", c.name, want, err)
+			}
+		}
+	}
+}
+
+func TestParseWorldDefUnknownField(t *testing.T) {
+	_, err := parseWorldDef(strings.NewReader(`{"start": "а", "roms": []}`))
+	if err == nil {
+		t.Fatalf("expected error for unknown field")
+	}
+}